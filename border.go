@@ -2,8 +2,6 @@ package flexpdf
 
 import (
 	"image/color"
-
-	"github.com/signintech/gopdf"
 )
 
 type BorderStyle int
@@ -16,43 +14,50 @@ const (
 
 type Border struct {
 	Color TRBL[color.Color]
-	Width Spacing
+	Width LengthSpacing
 	Style TRBL[BorderStyle]
 }
 
 func UniformedBorder(col color.Color, style BorderStyle, width float64) Border {
 	return Border{
 		Color: TRBL[color.Color]{col, col, col, col},
-		Width: Spacing{width, width, width, width},
+		Width: UniformedSpacing(Pt(width)),
 		Style: TRBL[BorderStyle]{style, style, style, style},
 	}
 }
 
-func (b *Border) draw(pdf *gopdf.GoPdf, r rect) (err error) {
+// draw は r の内側にボーダーを描画します。width は解決済みの Width です
+func (b *Border) draw(pdf *Document, r rect, width Spacing, clip *rect) (err error) {
 	defer wrap(&err, "border.draw")
 
-	r.x += b.Width.Left / 2
-	r.y += b.Width.Top / 2
-	r.w -= (b.Width.Left + b.Width.Right) / 2
-	r.h -= (b.Width.Top + b.Width.Bottom) / 2
+	r.x += width.Left / 2
+	r.y += width.Top / 2
+	r.w -= (width.Left + width.Right) / 2
+	r.h -= (width.Top + width.Bottom) / 2
 
-	if err := b.drawPart(pdf, r.x, r.y, r.x+r.w, r.y, b.Color.Top, b.Width.Top, b.Style.Top); err != nil {
+	if err := b.drawPart(pdf, clip, r.x, r.y, r.x+r.w, r.y, b.Color.Top, width.Top, b.Style.Top); err != nil {
 		return err
 	}
-	if err := b.drawPart(pdf, r.x+r.w, r.y, r.x+r.w, r.y+r.h, b.Color.Right, b.Width.Right, b.Style.Right); err != nil {
+	if err := b.drawPart(pdf, clip, r.x+r.w, r.y, r.x+r.w, r.y+r.h, b.Color.Right, width.Right, b.Style.Right); err != nil {
 		return err
 	}
-	if err := b.drawPart(pdf, r.x, r.y+r.h, r.x+r.w, r.y+r.h, b.Color.Bottom, b.Width.Bottom, b.Style.Bottom); err != nil {
+	if err := b.drawPart(pdf, clip, r.x, r.y+r.h, r.x+r.w, r.y+r.h, b.Color.Bottom, width.Bottom, b.Style.Bottom); err != nil {
 		return err
 	}
-	if err := b.drawPart(pdf, r.x, r.y, r.x, r.y+r.h, b.Color.Left, b.Width.Left, b.Style.Left); err != nil {
+	if err := b.drawPart(pdf, clip, r.x, r.y, r.x, r.y+r.h, b.Color.Left, width.Left, b.Style.Left); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (*Border) drawPart(pdf *gopdf.GoPdf, x1, y1, x2, y2 float64, col color.Color, width float64, style BorderStyle) error {
-	if col != nil && width > 0 {
+func (*Border) drawPart(pdf *Document, clip *rect, x1, y1, x2, y2 float64, col color.Color, width float64, style BorderStyle) error {
+	return strokeLine(pdf, clip, x1, y1, x2, y2, col, width, style)
+}
+
+// strokeLine はクリップ領域 clip に収まる範囲で、スタイルを指定した線分を描画します
+func strokeLine(pdf *Document, clip *rect, x1, y1, x2, y2 float64, col color.Color, width float64, style BorderStyle) error {
+	x1, y1, x2, y2, visible := clipLine(clip, x1, y1, x2, y2)
+	if col != nil && width > 0 && visible {
 		if err := setColor(pdf, col); err != nil {
 			return err
 		}
@@ -0,0 +1,77 @@
+package flexpdf
+
+import (
+	"fmt"
+	"image/color"
+)
+
+// TextDecorationLine はテキストに引く装飾線の種類です
+// https://www.w3.org/TR/css-text-decor-3/#text-decoration-line-property
+type TextDecorationLine string
+
+const (
+	TextDecorationUnderline   TextDecorationLine = "underline"    // 下線
+	TextDecorationOverline    TextDecorationLine = "overline"     // 上線
+	TextDecorationLineThrough TextDecorationLine = "line-through" // 取り消し線
+)
+
+// TextDecoration はテキストの装飾線です
+// https://www.w3.org/TR/css-text-decor-3/#line-decoration
+type TextDecoration struct {
+	Lines     []TextDecorationLine
+	Color     color.Color // nil の場合は文字の色を使用します
+	Thickness float64     // 0 の場合はフォントの下線の太さを使用します
+	Style     BorderStyle
+}
+
+func NewTextDecoration(col color.Color, style BorderStyle, thickness float64, lines ...TextDecorationLine) TextDecoration {
+	return TextDecoration{
+		Lines:     lines,
+		Color:     col,
+		Thickness: thickness,
+		Style:     style,
+	}
+}
+
+// drawDecoration は (x, y) を上端とする幅 w の noBrRun に装飾線を描画します。
+// 線の位置と太さには、フォントの下線のメトリクスを使用します
+func (r *noBrRun) drawDecoration(pdf *Document, x, y, w float64, clip *rect) error {
+	d := r.Decoration
+	if len(d.Lines) == 0 || w <= 0 {
+		return nil
+	}
+
+	fontSize := r.fontSize()
+	position, thickness := fontSize*defaultUnderlinePosition, fontSize*defaultDecorationThickness
+	strikeout := fontSize * defaultStrikeoutPosition
+	if face := r.font(pdf).face; face != nil {
+		position, thickness = face.underline(fontSize)
+		strikeout = face.strikeout(fontSize)
+	}
+	if d.Thickness > 0 {
+		thickness = d.Thickness
+	}
+	col := d.Color
+	if col == nil {
+		col = r.Color
+	}
+
+	baseline := y + r.ascent(pdf)
+	for _, line := range d.Lines {
+		var ly float64
+		switch line {
+		case TextDecorationUnderline:
+			ly = baseline + position
+		case TextDecorationOverline:
+			ly = y + thickness/2
+		case TextDecorationLineThrough:
+			ly = baseline - strikeout
+		default:
+			return fmt.Errorf("drawDecoration: unknown text decoration line %q", line)
+		}
+		if err := strokeLine(pdf, clip, x, ly, x+w, ly, col, thickness, d.Style); err != nil {
+			return err
+		}
+	}
+	return nil
+}
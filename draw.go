@@ -1,14 +1,36 @@
 package flexpdf
 
 import (
+	"errors"
+
 	"github.com/signintech/gopdf"
 )
 
-func Draw(pdf *gopdf.GoPdf, box *Box, pageSize *gopdf.Rect) error {
-	pdf.AddPageWithOption(gopdf.PageOption{PageSize: pageSize})
+// Draw は box を描画します。
+// box がページに収まらない場合、同じサイズのページを追加しながら残りの部分を描画します。
+// 描画中に box やその子孫を変更してはいけません
+func Draw(pdf *Document, box *Box, pageSize *gopdf.Rect) error {
+	pdf.contentSizes = map[contentSizeKey]size{}
+	defer func() { pdf.contentSizes = nil }()
+
+	var item FlexItem = box
+	for item != nil {
+		pdf.AddPageWithOption(gopdf.PageOption{PageSize: pageSize})
+
+		page := containingBlock{size: size{w: pageSize.W, h: pageSize.H}, fontSize: defaultFontSize}
+		head, tail, err := item.split(pdf, page, page.size, true)
+		if err != nil {
+			return err
+		}
+		if head == nil {
+			return errors.New("draw: item does not fit on an empty page")
+		}
+
+		if err := head.draw(pdf, page, rect{0, 0, pageSize.W, pageSize.H}, nil); err != nil {
+			return err
+		}
 
-	if err := box.draw(pdf, rect{0, 0, pageSize.W, pageSize.H}); err != nil {
-		return err
+		item = tail
 	}
 
 	return nil
@@ -7,6 +7,7 @@ import (
 	"bytes"
 	_ "embed"
 	"errors"
+	"flag"
 	"fmt"
 	"image"
 	"image/color"
@@ -26,7 +27,13 @@ var (
 	ipaexmBytes []byte
 )
 
-var errUnmatch = errors.New("unmatch")
+var (
+	errUnmatch = errors.New("unmatch")
+	errMissing = errors.New("missing (run go test -update to create it)")
+)
+
+// update が指定された場合、比較せずに描画結果で testdata/out の画像を上書きします
+var update = flag.Bool("update", false, "overwrite images in testdata/out")
 
 func TestMain(m *testing.M) {
 	code := (func() int {
@@ -45,13 +52,14 @@ const (
 Ut enim ad minim veniam, quis nostrud exercitation ullamco laboris nisi ut aliquip ex ea commodo consequat.
 
 Duis aute irure dolor in reprehenderit in voluptate velit esse cillum dolore eu fugiat nulla pariatur. Excepteur sint occaecat cupidatat non proident, sunt in culpa qui officia deserunt mollit anim id est laborum.`
+	jaText = `吾輩は猫である。名前はまだ無い。どこで生れたかとんと見当がつかぬ。何でも薄暗いじめじめした所でニャーニャー泣いていた事だけは記憶している。「吾輩はここで始めて人間というものを見た。」`
 )
 
 func TestDraw(t *testing.T) {
 	for name, box := range cases {
 		name, box := name, box
 		t.Run(name, func(t *testing.T) {
-			pdf := &gopdf.GoPdf{}
+			pdf := NewDocument(&gopdf.GoPdf{})
 			pdf.Start(gopdf.Config{})
 
 			if err := pdf.AddTTFFontData("ipaexg", ipaexgBytes); err != nil {
@@ -63,6 +71,13 @@ func TestDraw(t *testing.T) {
 			if err := pdf.AddTTFFontData("", ipaexgBytes); err != nil {
 				t.Fatal(err)
 			}
+			// 太字として明朝体を登録したファミリー
+			if err := pdf.AddTTFFontData("family", ipaexgBytes); err != nil {
+				t.Fatal(err)
+			}
+			if err := pdf.AddTTFFontFace("family", FontWeightBold, FontStyleNormal, ipaexmBytes); err != nil {
+				t.Fatal(err)
+			}
 
 			if err := Draw(pdf, box, gopdf.PageSizeA4); err != nil {
 				t.Fatal(err)
@@ -77,9 +92,21 @@ func TestDraw(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			if err := compareImage(data, fmt.Sprintf("testdata/out/%s.png", name)); err != nil {
+			images, err := getImages(data)
+			if err != nil {
 				t.Fatal(err)
 			}
+
+			for i, img := range images {
+				// 2ページ目以降はページ番号を付けたファイルと比較する
+				fileName := fmt.Sprintf("testdata/out/%s.png", name)
+				if i != 0 {
+					fileName = fmt.Sprintf("testdata/out/%s_%d.png", name, i+1)
+				}
+				if err := compareImage(img, fileName); err != nil {
+					t.Fatal(err)
+				}
+			}
 		})
 	}
 }
@@ -98,7 +125,7 @@ var cases = map[string]*Box{
 			NewText(NewRun(text)).SetBorder(UniformedBorder(colorR, BorderStyleDotted, 2)).SetAlign(TextAlignCenter),
 			NewText(NewRun(text)).SetBorder(UniformedBorder(colorR, BorderStyleDotted, 2)).SetAlign(TextAlignEnd),
 		),
-		NewRowBox( // TODO shrink
+		NewRowBox(
 			NewText(NewRun(text)).SetBorder(UniformedBorder(colorR, BorderStyleDotted, 2)).SetAlign(TextAlignBegin),
 			NewText(NewRun(text)).SetBorder(UniformedBorder(colorR, BorderStyleDotted, 2)).SetAlign(TextAlignCenter),
 			NewText(NewRun(text)).SetBorder(UniformedBorder(colorR, BorderStyleDotted, 2)).SetAlign(TextAlignEnd),
@@ -109,7 +136,7 @@ var cases = map[string]*Box{
 			NewText(NewRun("size").SetFontSize(60)),
 			NewText(NewRun("color").SetFontSize(30).SetColor(color.RGBA{R: 0xFF, A: 0xFF})),
 			NewText(NewRun("family").SetFontSize(30).SetFontFamily("ipaexm")),
-		),
+		).SetAlignItems(AlignItemsBaseline),
 		// multiple runs for 1 text
 		NewRowBox(
 			NewText(
@@ -130,14 +157,14 @@ var cases = map[string]*Box{
 		// 親がサイズ指定
 		NewRowBox().Configure(func(b *Box) {
 			b.Border = UniformedBorder(color.Black, BorderStyleDotted, 1)
-			b.Width = 200
+			b.Width = Pt(200)
 			b.Items = []FlexItem{
 				NewText(NewRun(text).SetFontSize(14)),
 			}
 		}),
 		// 自身がサイズ指定
 		NewRowBox().Configure(func(b *Box) {
-			b.Margin.Left = 80
+			b.Margin.Left = Pt(80)
 			b.Items = []FlexItem{
 				NewText(NewRun(text).SetFontSize(14)).SetBorder(UniformedBorder(color.Black, BorderStyleDotted, 1)).SetWidth(200),
 			}
@@ -163,25 +190,260 @@ var cases = map[string]*Box{
 		).SetMargin(30).SetBorder(UniformedBorder(color.Black, BorderStyleDashed, 1)),
 	).SetPadding(50).SetAlignItems(AlignItemsFlexStart),
 
+	"pagination": NewColumnBox(
+		NewText(NewRun("Pagination").SetFontSize(30)).SetMargin(0, 0, 20),
+		NewText(NewRun(text+"\n\n"+text+"\n\n"+text).SetFontSize(14)).SetBorder(UniformedBorder(colorR, BorderStyleDotted, 2)),
+		NewText(NewRun(text+"\n\n"+text+"\n\n"+text).SetFontSize(14)).SetBackgroundColor(colorG),
+		NewColumnBox(
+			NewText(NewRun(text).SetFontSize(14)).SetBackgroundColor(colorB),
+			NewText(NewRun(text).SetFontSize(14)).SetBackgroundColor(colorL),
+			NewText(NewRun(text).SetFontSize(14)).SetBackgroundColor(colorB),
+		).SetPadding(10).SetBorder(UniformedBorder(color.Black, BorderStyleDashed, 1)),
+		NewRowBox(
+			NewText(NewRun("row").SetFontSize(30)).SetFlexGrow(1).SetBackgroundColor(colorR),
+			NewText(NewRun("box").SetFontSize(30)).SetFlexGrow(1).SetBackgroundColor(colorG),
+		).SetHeight(200),
+	).SetPadding(50).SetAlignItems(AlignItemsFlexStart),
+
+	"flexwrap": NewColumnBox(
+		createFlexWrapExample(FlexWrapNoWrap),
+		createFlexWrapExample(FlexWrapWrap),
+		createFlexWrapExample(FlexWrapWrapReverse),
+	).SetPadding(30),
+
+	"aligncontent": NewRowBox(
+		createAlignContentExample(AlignContentFlexStart),
+		createAlignContentExample(AlignContentFlexEnd),
+		createAlignContentExample(AlignContentCenter),
+		createAlignContentExample(AlignContentSpaceBetween),
+		createAlignContentExample(AlignContentSpaceAround),
+		createAlignContentExample(AlignContentStretch),
+	).SetPadding(30).SetFlexWrap(FlexWrapWrap).SetAlignContent(AlignContentFlexStart),
+
+	"alignitems": NewColumnBox(
+		createAlignItemsExample(AlignItemsFlexStart),
+		createAlignItemsExample(AlignItemsFlexEnd),
+		createAlignItemsExample(AlignItemsCenter),
+		createAlignItemsExample(AlignItemsStretch),
+		createAlignItemsExample(AlignItemsBaseline),
+	).SetPadding(30),
+
+	"flexbasis": NewColumnBox(
+		createFlexBasisExample(FlexBasisAuto),
+		createFlexBasisExample(FlexBasisContent),
+		createFlexBasisExample(0),
+		createFlexBasisExample(100),
+	).SetPadding(30),
+
+	"minmax": NewColumnBox(
+		NewRowBox(
+			NewText(NewRun("max 100").SetFontSize(15)).SetFlexGrow(1).SetMaxWidth(100).SetBackgroundColor(colorR),
+			NewText(NewRun("grow").SetFontSize(15)).SetFlexGrow(1).SetBackgroundColor(colorG),
+			NewText(NewRun("grow").SetFontSize(15)).SetFlexGrow(1).SetBackgroundColor(colorB),
+		).SetMargin(0, 0, 20),
+		NewRowBox(
+			NewText(NewRun(text).SetFontSize(15)).SetMinWidth(300).SetBackgroundColor(colorR),
+			NewText(NewRun(text).SetFontSize(15)).SetBackgroundColor(colorG),
+		).SetMargin(0, 0, 20),
+		NewRowBox(
+			NewText(NewRun("min 200").SetFontSize(15)).SetWidth(100).SetMinWidth(200).SetBackgroundColor(colorR),
+			NewText(NewRun("min height 80").SetFontSize(15)).SetMinHeight(80).SetAlignSelf(AlignSelfFlexStart).SetBackgroundColor(colorG),
+			NewText(NewRun("max height 30").SetFontSize(15)).SetMaxHeight(30).SetBackgroundColor(colorB),
+		).SetMargin(0, 0, 20),
+	).SetPadding(30),
+
+	"gap": NewColumnBox(
+		NewRowBox(
+			NewText(NewRun("A").SetFontSize(15)).SetFlexGrow(1).SetBackgroundColor(colorR),
+			NewText(NewRun("B").SetFontSize(15)).SetFlexGrow(1).SetBackgroundColor(colorG),
+			NewText(NewRun("C").SetFontSize(15)).SetFlexGrow(1).SetBackgroundColor(colorB),
+		).SetGap(10),
+		createFlexWrapExample(FlexWrapWrap).Configure(func(b *Box) {
+			b.Items[1].(*Box).SetRowGap(20).SetColumnGap(5)
+		}),
+	).SetPadding(30).SetRowGap(40),
+
+	"automargin": NewColumnBox(
+		NewRowBox(
+			NewText(NewRun("Item").SetFontSize(15)).SetBackgroundColor(colorL),
+			NewText(NewRun("Qty").SetFontSize(15)).SetBackgroundColor(colorL),
+			NewText(NewRun("Total").SetFontSize(15)).SetMarginLength(Pt(0), Pt(0), Pt(0), Auto).SetBackgroundColor(colorR),
+		).SetGap(10).SetBorder(UniformedBorder(color.Black, BorderStyleSolid, 1)),
+		NewRowBox(
+			NewText(NewRun("centered").SetFontSize(15)).SetMarginLength(Auto).SetBackgroundColor(colorG),
+		).SetHeight(60).SetBorder(UniformedBorder(color.Black, BorderStyleSolid, 1)),
+		NewColumnBox(
+			NewText(NewRun("top").SetFontSize(15)).SetBackgroundColor(colorB),
+			NewText(NewRun("bottom, right").SetFontSize(15)).SetMarginLength(Auto, Pt(0), Pt(0), Auto).SetBackgroundColor(colorB),
+		).SetHeight(100).SetBorder(UniformedBorder(color.Black, BorderStyleSolid, 1)),
+	).SetPadding(30).SetGap(20),
+
+	"length": NewColumnBox(
+		NewRowBox(
+			NewText(NewRun("25%").SetFontSize(15)).SetWidthLength(Percent(25)).SetBackgroundColor(colorR),
+			NewText(NewRun("50%").SetFontSize(15)).SetWidthLength(Percent(50)).SetBackgroundColor(colorG),
+			NewText(NewRun("25%").SetFontSize(15)).SetWidthLength(Percent(25)).SetBackgroundColor(colorB),
+		),
+		NewRowBox(
+			NewText(NewRun("30mm").SetFontSize(15)).SetWidthLength(Mm(30)).SetBackgroundColor(colorR),
+			NewText(NewRun("3cm").SetFontSize(15)).SetWidthLength(Cm(3)).SetBackgroundColor(colorG),
+			NewText(NewRun("1in").SetFontSize(15)).SetWidthLength(In(1)).SetBackgroundColor(colorB),
+		),
+		NewRowBox(
+			NewText(NewRun("padding 1em").SetFontSize(15)).SetPaddingLength(Em(1)).SetBackgroundColor(colorR),
+			NewText(NewRun("padding 1em").SetFontSize(30)).SetPaddingLength(Em(1)).SetBackgroundColor(colorG),
+			NewText(NewRun("2em").SetFontSizeLength(Em(2))).SetMarginLength(Pt(0), Percent(10)).SetBackgroundColor(colorB),
+		).SetAlignItems(AlignItemsFlexStart),
+	).SetPaddingLength(Mm(10)).SetGap(20),
+
+	"order": NewColumnBox(
+		NewRowBox(
+			NewText(NewRun("A (order 0)").SetFontSize(15)).SetBackgroundColor(colorR),
+			NewText(NewRun("B (order -1)").SetFontSize(15)).SetOrder(-1).SetBackgroundColor(colorG),
+			NewText(NewRun("C (order 1)").SetFontSize(15)).SetOrder(1).SetBackgroundColor(colorB),
+			NewText(NewRun("D (order 0)").SetFontSize(15)).SetBackgroundColor(colorL),
+		).SetGap(10),
+		NewText(NewRun("summary (order -1)").SetFontSize(15)).SetOrder(-1).SetBackgroundColor(colorG),
+	).SetPadding(30).SetGap(20),
+
+	"position": NewColumnBox(
+		NewRowBox(
+			NewText(NewRun("static").SetFontSize(15)).SetBackgroundColor(colorR),
+			NewText(NewRun("relative").SetFontSize(15)).SetPosition(PositionRelative).SetInset(Pt(10), Auto, Auto, Pt(10)).SetBackgroundColor(colorG),
+			NewText(NewRun("static").SetFontSize(15)).SetBackgroundColor(colorB),
+		).SetGap(10),
+		NewRowBox(
+			NewText(NewRun(text).SetFontSize(14)),
+			NewText(NewRun("PAID").SetFontSize(30).SetColor(colorR)).SetPosition(PositionAbsolute).SetInset(Pt(10), Pt(10), Auto, Auto).SetBorder(UniformedBorder(colorR, BorderStyleSolid, 3)),
+			NewText(NewRun("bottom left").SetFontSize(10)).SetPosition(PositionAbsolute).SetInset(Auto, Auto, Pt(0), Pt(0)).SetBackgroundColor(colorL),
+			NewText(NewRun("stretched").SetFontSize(10)).SetPosition(PositionAbsolute).SetInset(Auto, Percent(25), Pt(0)).SetBackgroundColor(colorB),
+		).SetPadding(20).SetBorder(UniformedBorder(color.Black, BorderStyleSolid, 1)),
+	).SetPadding(30).SetGap(20),
+
+	"aspectratio": NewColumnBox(
+		NewRowBox(
+			NewText(NewRun("1:1").SetFontSize(15)).SetFlex(1, 1, 0).SetAspectRatio(1).SetBackgroundColor(colorR),
+			NewText(NewRun("16:9").SetFontSize(15)).SetFlex(2, 1, 0).SetAspectRatio(16.0/9).SetBackgroundColor(colorG),
+			NewText(NewRun("height 50").SetFontSize(15)).SetHeight(50).SetAspectRatio(2).SetBackgroundColor(colorB),
+		).SetGap(10).SetAlignItems(AlignItemsFlexStart),
+		NewBox(DirectionColumn).SetWidth(100).SetAspectRatio(1).SetBackgroundColor(colorL),
+		NewBox(DirectionColumn).SetAspectRatio(4).SetBackgroundColor(colorL),
+	).SetPadding(30).SetGap(20),
+
+	"grid": NewColumnBox(
+		NewGrid(
+			[]TrackSize{TrackFixed(Pt(80)), TrackFr(1), TrackAuto, TrackMinMaxFr(Pt(100), 2)},
+			NewText(NewRun("fixed 80").SetFontSize(15)).SetBackgroundColor(colorR),
+			NewText(NewRun("1fr").SetFontSize(15)).SetBackgroundColor(colorG),
+			NewText(NewRun("auto").SetFontSize(15)).SetBackgroundColor(colorB),
+			NewText(NewRun("minmax(100, 2fr)").SetFontSize(15)).SetBackgroundColor(colorL),
+			NewText(NewRun("span 2 rows").SetFontSize(15)).SetGridSpan(2, 1).SetBackgroundColor(colorR),
+			NewText(NewRun(text).SetFontSize(12)).SetGridSpan(1, 3).SetBackgroundColor(colorG),
+			NewText(NewRun("row 3, column 4").SetFontSize(15)).SetGridArea(3, 4, 1, 1).SetBackgroundColor(colorB),
+			NewText(NewRun("auto").SetFontSize(15)).SetBackgroundColor(colorL),
+		).SetGap(10).SetBorder(UniformedBorder(color.Black, BorderStyleSolid, 1)),
+		NewGrid(
+			[]TrackSize{TrackFr(1), TrackFr(1), TrackFr(1)},
+			NewText(NewRun("label 1").SetFontSize(15)).SetPadding(10).SetBorder(UniformedBorder(color.Black, BorderStyleDashed, 1)),
+			NewText(NewRun("label 2").SetFontSize(15)).SetPadding(10).SetBorder(UniformedBorder(color.Black, BorderStyleDashed, 1)),
+			NewText(NewRun("label 3").SetFontSize(15)).SetPadding(10).SetBorder(UniformedBorder(color.Black, BorderStyleDashed, 1)),
+			NewText(NewRun("label 4").SetFontSize(15)).SetPadding(10).SetBorder(UniformedBorder(color.Black, BorderStyleDashed, 1)),
+		).SetRows(TrackFixed(Pt(60))).SetAutoRows(TrackFixed(Pt(40))).SetGap(5),
+	).SetPadding(30).SetGap(20),
+
+	"overflow": NewColumnBox(
+		NewRowBox(
+			NewText(NewRun("visible").SetFontSize(15)).SetSize(40, 20).SetBackgroundColor(colorR),
+			NewText(NewRun("hidden").SetFontSize(15)).SetSize(40, 20).SetOverflow(OverflowHidden).SetBackgroundColor(colorG),
+			NewText(NewRun(text).SetFontSize(12)).SetSize(150, 50).SetOverflow(OverflowHidden).SetPadding(5).SetBorder(UniformedBorder(color.Black, BorderStyleSolid, 1)),
+		).SetGap(40),
+		NewColumnBox(
+			NewText(NewRun(text).SetFontSize(14)).SetWidth(400).SetBackgroundColor(colorB).SetBorder(UniformedBorder(color.Black, BorderStyleDashed, 1)),
+			NewText(NewRun("PAID").SetFontSize(40).SetColor(colorR)).SetPosition(PositionAbsolute).SetInset(Auto, Pt(-20), Pt(-20), Auto).SetBackgroundColor(colorL),
+		).SetSize(300, 80).SetOverflow(OverflowHidden).SetBorder(UniformedBorder(color.Black, BorderStyleSolid, 2)),
+	).SetPadding(30).SetGap(40),
+
+	"maxlines": NewColumnBox(
+		NewText(NewRun(text).SetFontSize(14)).SetMaxLines(2).SetBackgroundColor(colorR),
+		NewText(NewRun(text).SetFontSize(14)).SetMaxLines(2).SetTextOverflow(TextOverflowEllipsis).SetBackgroundColor(colorG),
+		NewText(
+			NewRun("東京都千代田区千代田1-1 ").SetFontSize(14),
+			NewRun("テストビルディング 12345号室 株式会社サンプル 御中").SetFontSize(14).SetColor(colorR),
+		).SetWidth(200).SetMaxLines(1).SetTextOverflow(TextOverflowEllipsis).SetBackgroundColor(colorB),
+	).SetPadding(30).SetGap(20).SetAlignItems(AlignItemsFlexStart),
+
+	"linebreak": NewRowBox(
+		NewText(NewRun(text).SetFontSize(14)).SetWidth(150).SetBorder(UniformedBorder(color.Black, BorderStyleDotted, 1)),
+		NewText(NewRun("state-of-the-art well-known    trailing spaces    ").SetFontSize(14)).SetWidth(100).SetAlign(TextAlignEnd).SetBorder(UniformedBorder(color.Black, BorderStyleDotted, 1)),
+		NewText(NewRun("Pneumonoultramicroscopicsilicovolcanoconiosis is long").SetFontSize(14)).SetWidth(100).SetBorder(UniformedBorder(color.Black, BorderStyleDotted, 1)),
+		NewText(NewRun("English と日本語の混在した文章 mixed text").SetFontSize(14)).SetWidth(100).SetBorder(UniformedBorder(color.Black, BorderStyleDotted, 1)),
+	).SetPadding(30).SetGap(10).SetAlignItems(AlignItemsFlexStart),
+
+	"kinsoku": NewRowBox(
+		NewText(NewRun(jaText).SetFontSize(14)).SetWidth(98).SetKinsoku(KinsokuNone).SetBorder(UniformedBorder(color.Black, BorderStyleDotted, 1)),
+		NewText(NewRun(jaText).SetFontSize(14)).SetWidth(98).SetBorder(UniformedBorder(color.Black, BorderStyleDotted, 1)),
+		NewText(NewRun(jaText).SetFontSize(14)).SetWidth(98).SetKinsoku(KinsokuPushIn).SetBorder(UniformedBorder(color.Black, BorderStyleDotted, 1)),
+		NewText(NewRun(jaText).SetFontSize(14)).SetWidth(98).SetHangingPunctuation(true).SetBorder(UniformedBorder(color.Black, BorderStyleDotted, 1)),
+	).SetPadding(30).SetGap(20).SetAlignItems(AlignItemsFlexStart),
+
+	"justify": NewRowBox(
+		NewText(NewRun(text)).SetWidth(150).SetAlign(TextAlignJustify).SetBorder(UniformedBorder(color.Black, BorderStyleDotted, 1)),
+		NewText(NewRun(text)).SetWidth(150).SetAlign(TextAlignJustify).SetAlignLast(TextAlignEnd).SetBorder(UniformedBorder(color.Black, BorderStyleDotted, 1)),
+		NewText(NewRun(jaText).SetFontSize(14), NewRun("English words").SetFontSize(14)).SetWidth(100).SetAlign(TextAlignJustify).SetAlignLast(TextAlignJustify).SetBorder(UniformedBorder(color.Black, BorderStyleDotted, 1)),
+	).SetPadding(30).SetGap(20).SetAlignItems(AlignItemsFlexStart),
+
+	"fontweight": NewColumnBox(
+		NewText(
+			NewRun("Normal ").SetFontSize(20),
+			NewRun("Bold ").SetFontSize(20).SetFontWeight(FontWeightBold),
+			NewRun("Italic ").SetFontSize(20).SetFontStyle(FontStyleItalic),
+			NewRun("Bold Italic 太字斜体").SetFontSize(20).SetFontWeight(FontWeightBold).SetFontStyle(FontStyleItalic),
+		),
+		NewText(
+			NewRun("Normal ").SetFontSize(20).SetFontFamily("family"),
+			NewRun("Registered Bold ").SetFontSize(20).SetFontFamily("family").SetFontWeight(FontWeightBold),
+			NewRun("Black 900").SetFontSize(20).SetFontFamily("family").SetFontWeight(900),
+		),
+	).SetPadding(30).SetGap(20),
+
+	"fallback": NewColumnBox(
+		NewText(NewRun("Ελληνικά Кириллица 日本語 𠮷野家 ☃").SetFontSize(20).SetFontFamily("ipaexm").SetFallbackFamilies("ipaexg")),
+	).SetPadding(30),
+
+	"decoration": NewColumnBox(
+		NewText(
+			NewRun("定価 ").SetFontSize(20),
+			NewRun("¥12,800").SetFontSize(20).SetDecoration(NewTextDecoration(color.RGBA{R: 0xFF, A: 0xFF}, BorderStyleSolid, 0, TextDecorationLineThrough)),
+			NewRun(" → ¥9,800").SetFontSize(20),
+		),
+		NewText(
+			NewRun("Name: ").SetFontSize(20),
+			NewRun("　　　　　　　　").SetFontSize(20).SetDecoration(NewTextDecoration(nil, BorderStyleSolid, 0, TextDecorationUnderline)),
+		),
+		NewText(
+			NewRun("Underline and overline, dashed").SetFontSize(20).SetDecoration(NewTextDecoration(colorB, BorderStyleDashed, 1, TextDecorationUnderline, TextDecorationOverline)),
+		),
+		NewText(NewRun(text).SetDecoration(NewTextDecoration(nil, BorderStyleDotted, 0, TextDecorationUnderline))).SetAlign(TextAlignJustify),
+	).SetPadding(30).SetGap(20),
+
+	"table": NewColumnBox(
+		NewText(NewRun("Invoice").SetFontSize(30)),
+		createTableExample(),
+	).SetPadding(30).SetGap(20),
+
 	"justifycontent": NewColumnBox(
 		createJustifyContentExamples(DirectionColumn, DirectionRow),
 		createJustifyContentExamples(DirectionRow, DirectionColumn),
 	),
+	"justifycontent_reverse": NewColumnBox(
+		createJustifyContentExamples(DirectionColumn, DirectionRowReverse),
+		createJustifyContentExamples(DirectionRow, DirectionColumnReverse),
+	),
 }
 
-func compareImage(pdfBytes []byte, fileName string) (err error) {
+func compareImage(imgGot image.Image, fileName string) (err error) {
 	defer wrap(&err, "compareImage")
 
-	images, err := getImages(pdfBytes)
-	if err != nil {
-		return err
-	}
-
-	if len(images) != 1 {
-		return fmt.Errorf("len(images) = %d", len(images))
-	}
-
-	imgGot := images[0]
 	var bytesGot []byte
 
 	{
@@ -192,11 +454,15 @@ func compareImage(pdfBytes []byte, fileName string) (err error) {
 		bytesGot = buf.Bytes()
 	}
 
+	if *update {
+		return os.WriteFile(fileName, bytesGot, 0666)
+	}
+
 	bytesWant, err := os.ReadFile(fileName)
 
 	if errors.Is(err, os.ErrNotExist) {
-		// 存在しない場合は保存する。比較はしない
-		return os.WriteFile(fileName, bytesGot, 0666)
+		// 存在しない場合は失敗とする。-update で保存する
+		return fmt.Errorf("%s: %w", fileName, errMissing)
 	} else if err != nil {
 		return err
 	}
@@ -281,6 +547,7 @@ func createJustifyContentExamples(dir1, dir2 Direction) *Box {
 		createJustifyContentExample(dir2, JustifyContentCenter),
 		createJustifyContentExample(dir2, JustifyContentSpaceBetween),
 		createJustifyContentExample(dir2, JustifyContentSpaceAround),
+		createJustifyContentExample(dir2, JustifyContentSpaceEvenly),
 	).SetMargin(
 		20,
 	).SetBorder(
@@ -311,3 +578,98 @@ func createJustifyContentExample(dir Direction, jc JustifyContent) *Box {
 		).SetFlexGrow(1),
 	)
 }
+
+func createFlexWrapExample(fw FlexWrap) *Box {
+	tiles := []FlexItem{}
+	for i, word := range strings.Fields("Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor incididunt ut labore et dolore magna aliqua.") {
+		tiles = append(tiles, NewText(NewRun(word).SetFontSize(float64(14+i%3*6))).SetPadding(5).SetMargin(3).SetBackgroundColor([]color.Color{colorR, colorG, colorB}[i%3]))
+	}
+	return NewColumnBox(
+		NewText(NewRun(string(fw)).SetFontSize(14)),
+		NewRowBox(tiles...).SetFlexWrap(fw).SetBorder(UniformedBorder(color.Black, BorderStyleDashed, 1)),
+	).SetMargin(0, 0, 20)
+}
+
+func createAlignContentExample(ac AlignContent) *Box {
+	return NewColumnBox(
+		NewText(NewRun(string(ac)).SetFontSize(14)),
+		NewRowBox(
+			NewText(NewRun("A").SetFontSize(15)).SetSize(60, 20).SetBackgroundColor(colorR),
+			NewText(NewRun("B").SetFontSize(15)).SetSize(60, 20).SetBackgroundColor(colorG),
+			NewText(NewRun("C").SetFontSize(15)).SetSize(60, 20).SetBackgroundColor(colorB),
+			NewText(NewRun("D").SetFontSize(15)).SetSize(60, 20).SetBackgroundColor(colorL),
+			NewText(NewRun("E").SetFontSize(15)).SetSize(60, 20).SetBackgroundColor(colorR),
+		).SetSize(150, 150).SetFlexWrap(
+			FlexWrapWrap,
+		).SetAlignContent(
+			ac,
+		).SetBorder(
+			UniformedBorder(color.Black, BorderStyleSolid, 2),
+		),
+	).SetMargin(10)
+}
+
+func createAlignItemsExample(ai AlignItems) *Box {
+	return NewColumnBox(
+		NewText(NewRun(string(ai)).SetFontSize(14)),
+		NewRowBox(
+			NewText(NewRun("A").SetFontSize(15)).SetBackgroundColor(colorR),
+			NewText(NewRun("B").SetFontSize(30)).SetBackgroundColor(colorG),
+			NewText(NewRun("C").SetFontSize(15)).SetHeight(40).SetBackgroundColor(colorB),
+			NewText(NewRun("self: flex-end").SetFontSize(15)).SetAlignSelf(AlignSelfFlexEnd).SetBackgroundColor(colorL),
+			NewText(NewRun("self: center").SetFontSize(15)).SetAlignSelf(AlignSelfCenter).SetBackgroundColor(colorR),
+		).SetHeight(80).SetAlignItems(
+			ai,
+		).SetBorder(
+			UniformedBorder(color.Black, BorderStyleSolid, 2),
+		),
+	).SetMargin(0, 0, 20)
+}
+
+func createFlexBasisExample(basis FlexBasis) *Box {
+	return NewColumnBox(
+		NewText(NewRun(fmt.Sprintf("flex: 1 1 %v", basis)).SetFontSize(14)),
+		NewRowBox(
+			NewText(NewRun("A").SetFontSize(15)).SetFlex(1, 1, basis).SetBackgroundColor(colorR),
+			NewText(NewRun("BBBBBBBBBB").SetFontSize(15)).SetFlex(1, 1, basis).SetBackgroundColor(colorG),
+			NewText(NewRun("CCCCC").SetFontSize(15)).SetFlex(1, 1, basis).SetWidth(150).SetBackgroundColor(colorB),
+		).SetBorder(
+			UniformedBorder(color.Black, BorderStyleSolid, 2),
+		),
+	).SetMargin(0, 0, 20)
+}
+
+func createTableExample() *Table {
+	cell := func(s string) *Text {
+		return NewText(NewRun(s).SetFontSize(12)).SetPadding(4)
+	}
+
+	t := NewTable(TrackAuto, TrackFr(1), TrackFixed(Pt(60)), TrackFixed(Pt(80))).
+		SetHeaderRows(1).
+		SetCellBorder(UniformedBorder(color.Black, BorderStyleSolid, 1))
+	t.AddRow(
+		cell("No.").SetBackgroundColor(colorL),
+		cell("Description").SetBackgroundColor(colorL),
+		cell("Qty").SetBackgroundColor(colorL),
+		cell("Amount").SetBackgroundColor(colorL),
+	)
+	for i := 1; i <= 40; i++ {
+		t.AddRow(cell(fmt.Sprint(i)), cell(text[:20+i*3]), cell("1"), cell("1,000"))
+	}
+	t.AddRow(cell("").SetGridSpan(2, 2), cell("Subtotal").SetBackgroundColor(colorG), cell("40,000"))
+	t.AddRow(cell("Tax").SetBackgroundColor(colorG), cell("4,000"))
+	t.AddRow(cell("Total").SetGridSpan(1, 3).SetBackgroundColor(colorR), cell("44,000"))
+	return t
+}
+
+// newTestPdf はデフォルトのフォントを登録したテスト用のPDFを返します
+func newTestPdf(t *testing.T) *Document {
+	t.Helper()
+	pdf := NewDocument(&gopdf.GoPdf{})
+	pdf.Start(gopdf.Config{})
+	if err := pdf.AddTTFFontData("", ipaexgBytes); err != nil {
+		t.Fatal(err)
+	}
+	pdf.AddPage()
+	return pdf
+}
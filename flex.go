@@ -1,10 +1,13 @@
 package flexpdf
 
+// https://www.w3.org/TR/css-flexbox/#flex-direction-property
 type Direction int
 
 const (
 	DirectionRow Direction = iota
 	DirectionColumn
+	DirectionRowReverse
+	DirectionColumnReverse
 )
 
 func (d Direction) String() string {
@@ -13,21 +16,49 @@ func (d Direction) String() string {
 		return "row"
 	case DirectionColumn:
 		return "column"
+	case DirectionRowReverse:
+		return "row-reverse"
+	case DirectionColumnReverse:
+		return "column-reverse"
 	default:
 		panic(d)
 	}
 }
 func (d Direction) mainAxis() axis {
 	switch d {
-	case DirectionRow:
+	case DirectionRow, DirectionRowReverse:
 		return horizontal
-	case DirectionColumn:
+	case DirectionColumn, DirectionColumnReverse:
 		return vertical
 	default:
 		panic(d)
 	}
 }
 
+// isReverse は主軸の始端と終端が入れ替わっているかどうかを返します
+func (d Direction) isReverse() bool {
+	return d == DirectionRowReverse || d == DirectionColumnReverse
+}
+
+// https://www.w3.org/TR/css-flexbox/#flex-wrap-property
+type FlexWrap string
+
+const (
+	FlexWrapNoWrap      FlexWrap = "nowrap"
+	FlexWrapWrap        FlexWrap = "wrap"
+	FlexWrapWrapReverse FlexWrap = "wrap-reverse"
+)
+
+// FlexBasis はフレックスベースサイズ（グロー・シュリンクを行う前の主軸のコンテンツボックスのサイズ）です
+// 0以上の値はそのサイズを表します
+// https://www.w3.org/TR/css-flexbox/#flex-basis-property
+type FlexBasis float64
+
+const (
+	FlexBasisAuto    FlexBasis = -1 // Width/Height が指定されていればそのサイズ、なければ内容のサイズ
+	FlexBasisContent FlexBasis = -2 // Width/Height に関わらず内容のサイズ
+)
+
 // https://www.w3.org/TR/css-flexbox/#justify-content-property
 type JustifyContent string
 
@@ -37,7 +68,7 @@ const (
 	JustifyContentCenter       JustifyContent = "center"
 	JustifyContentSpaceBetween JustifyContent = "space-between"
 	JustifyContentSpaceAround  JustifyContent = "space-around"
-	// JustifyContentSpaceEvenly  JustifyContent = "space-evenly"
+	JustifyContentSpaceEvenly  JustifyContent = "space-evenly"
 )
 
 // https://www.w3.org/TR/css-flexbox/#propdef-align-items
@@ -48,4 +79,37 @@ const (
 	AlignItemsFlexEnd   AlignItems = "flex-end"
 	AlignItemsCenter    AlignItems = "center"
 	AlignItemsStretch   AlignItems = "stretch"
+	AlignItemsBaseline  AlignItems = "baseline"
+)
+
+// https://www.w3.org/TR/css-flexbox/#propdef-align-self
+type AlignSelf string
+
+const (
+	AlignSelfAuto      AlignSelf = "auto"
+	AlignSelfFlexStart AlignSelf = "flex-start"
+	AlignSelfFlexEnd   AlignSelf = "flex-end"
+	AlignSelfCenter    AlignSelf = "center"
+	AlignSelfStretch   AlignSelf = "stretch"
+	AlignSelfBaseline  AlignSelf = "baseline"
+)
+
+// resolve は auto を親の AlignItems で置き換えた値を返します
+func (as AlignSelf) resolve(ai AlignItems) AlignItems {
+	if as == AlignSelfAuto || as == "" {
+		return ai
+	}
+	return AlignItems(as)
+}
+
+// https://www.w3.org/TR/css-flexbox/#align-content-property
+type AlignContent string
+
+const (
+	AlignContentFlexStart    AlignContent = "flex-start"
+	AlignContentFlexEnd      AlignContent = "flex-end"
+	AlignContentCenter       AlignContent = "center"
+	AlignContentSpaceBetween AlignContent = "space-between"
+	AlignContentSpaceAround  AlignContent = "space-around"
+	AlignContentStretch      AlignContent = "stretch"
 )
@@ -0,0 +1,270 @@
+package flexpdf
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/signintech/gopdf"
+	"github.com/signintech/gopdf/fontmaker/core"
+)
+
+// フォントのメトリクスが得られない場合に使用する、フォントサイズに対する比率
+const (
+	defaultAscender            = 0.8  // アセンダー
+	defaultDescender           = 0.2  // ディセンダー（ベースラインから下向きが正）
+	defaultUnderlinePosition   = 0.1  // ベースラインから下線までの距離
+	defaultStrikeoutPosition   = 0.25 // ベースラインから取り消し線までの高さ
+	defaultDecorationThickness = 0.05 // 装飾線の太さ
+)
+
+// FontWeight はフォントの太さです。100 から 900 の値を取ります
+// https://www.w3.org/TR/css-fonts-4/#font-weight-prop
+type FontWeight int
+
+const (
+	FontWeightNormal FontWeight = 400
+	FontWeightBold   FontWeight = 700
+)
+
+// FontStyle はフォントのスタイルです
+// https://www.w3.org/TR/css-fonts-4/#font-style-prop
+type FontStyle string
+
+const (
+	FontStyleNormal  FontStyle = "normal"
+	FontStyleItalic  FontStyle = "italic"
+	FontStyleOblique FontStyle = "oblique"
+)
+
+const (
+	syntheticBoldWeight   = 600  // この太さ以上を要求され、それより細いフォントしかない場合に太字を合成します
+	syntheticBoldOffset   = 0.03 // 太字の合成で重ねて描画する際の、フォントサイズに対するずれの比率
+	syntheticObliqueAngle = 12   // 斜体の合成で文字を傾ける角度
+)
+
+// Document は flexpdf で描画する文書です。
+// gopdf.GoPdf に、flexpdf に登録したフォントのメトリクスを加えたものです
+type Document struct {
+	*gopdf.GoPdf
+	fonts        *fontRegistry
+	contentSizes map[contentSizeKey]size // Draw の間、求めた内容のサイズを保持します
+}
+
+// NewDocument は pdf に描画する Document を返します。フォントは Document を通して追加します
+func NewDocument(pdf *gopdf.GoPdf) *Document {
+	return &Document{
+		GoPdf: pdf,
+		fonts: &fontRegistry{faces: map[string][]*fontFace{}, selections: map[fontQuery]fontSelection{}},
+	}
+}
+
+// fontRegistry は1つの文書に登録されたフォントです
+type fontRegistry struct {
+	mu         sync.RWMutex
+	faces      map[string][]*fontFace      // ファミリー名ごとのフォント
+	selections map[fontQuery]fontSelection // selectFont の結果のキャッシュ
+}
+
+// fontQuery は selectFont に渡すフォントの条件です
+type fontQuery struct {
+	family string
+	weight FontWeight
+	style  FontStyle
+}
+
+// add は face を登録し、同じファミリー・太さ・スタイルのフォントを置き換えます
+func (reg *fontRegistry) add(family string, face *fontFace) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	faces := []*fontFace{}
+	for _, f := range reg.faces[family] {
+		if f.weight != face.weight || f.style != face.style {
+			faces = append(faces, f)
+		}
+	}
+	reg.faces[family] = append(faces, face)
+	reg.selections = map[fontQuery]fontSelection{}
+}
+
+// fontFace は flexpdf に登録された TTF フォントです
+type fontFace struct {
+	name   string // gopdf に追加したフォントの名前
+	weight FontWeight
+	style  FontStyle
+	ttfp   core.TTFParser
+}
+
+// ascender はフォントサイズ fontSize におけるアセンダーの高さを返します。
+// gopdf は Cell の上端からこの距離にベースラインを置きます。
+func (f *fontFace) ascender(fontSize float64) float64 {
+	return float64(f.ttfp.TypoAscender()) * fontSize / float64(f.ttfp.UnitsPerEm())
+}
+
+// descender はフォントサイズ fontSize における、ベースラインからディセンダーまでの深さ（下向きが正）を返します
+func (f *fontFace) descender(fontSize float64) float64 {
+	return -float64(f.ttfp.TypoDescender()) * fontSize / float64(f.ttfp.UnitsPerEm())
+}
+
+// underline はフォントサイズ fontSize における、ベースラインから下線の中心までの距離（下向きが正）と下線の太さを返します
+func (f *fontFace) underline(fontSize float64) (position, thickness float64) {
+	scale := fontSize / float64(f.ttfp.UnitsPerEm())
+	position = -float64(f.ttfp.UnderlinePosition()) * scale
+	thickness = float64(f.ttfp.UnderlineThickness()) * scale
+	if thickness <= 0 {
+		position, thickness = fontSize*defaultUnderlinePosition, fontSize*defaultDecorationThickness
+	}
+	return position, thickness
+}
+
+// strikeout はフォントサイズ fontSize における、ベースラインから取り消し線の中心までの高さを返します。
+// gopdf は OS/2 テーブルの取り消し線の位置を公開していないため、x-height の半分とします
+func (f *fontFace) strikeout(fontSize float64) float64 {
+	if xh := f.ttfp.XHeight(); xh > 0 {
+		return float64(xh) * fontSize / float64(f.ttfp.UnitsPerEm()) / 2
+	}
+	return fontSize * defaultStrikeoutPosition
+}
+
+// hasGlyph はフォントが ch の字形を持つかどうかを cmap から調べます
+func (f *fontFace) hasGlyph(ch rune) bool {
+	if glyph, ok := f.ttfp.Chars()[int(ch)]; ok && glyph != 0 {
+		return true
+	}
+	for _, g := range f.ttfp.GroupingTables() {
+		if g.StartCharCode <= uint(ch) && uint(ch) <= g.EndCharCode {
+			return true
+		}
+	}
+	return false
+}
+
+// AddTTFFontData は TTF フォントを family の名前で文書に追加し、
+// ベースラインの計算などに使用するメトリクスを flexpdf に登録します。
+// gopdf.GoPdf.AddTTFFontData で直接追加したフォントはメトリクスが得られないため、
+// ベースラインなどをフォントサイズに対する既定の比率で近似します
+func (d *Document) AddTTFFontData(family string, fontData []byte) error {
+	return d.AddTTFFontFace(family, FontWeightNormal, FontStyleNormal, fontData)
+}
+
+// AddTTFFontFace は TTF フォントを family の weight・style のフォントとして文書に追加し、flexpdf に登録します。
+// TextRun の FontWeight・FontStyle に一致するフォントがない場合は、最も近いフォントが選ばれ、
+// 必要に応じて太字や斜体が合成されます
+func (d *Document) AddTTFFontFace(family string, weight FontWeight, style FontStyle, fontData []byte) (err error) {
+	defer wrap(&err, "AddTTFFontFace")
+
+	face := &fontFace{name: family, weight: weight, style: style}
+	if weight != FontWeightNormal || style != FontStyleNormal {
+		face.name = fmt.Sprintf("%s:%d:%s", family, weight, style)
+	}
+	if err := face.ttfp.ParseFontData(fontData); err != nil {
+		return err
+	}
+
+	if err := d.GoPdf.AddTTFFontData(face.name, fontData); err != nil {
+		return err
+	}
+	d.fonts.add(family, face)
+	return nil
+}
+
+// fontSelection は TextRun の描画に使用するフォントです
+type fontSelection struct {
+	face             *fontFace // 登録されていないファミリーの場合は nil
+	name             string    // gopdf に追加したフォントの名前
+	syntheticBold    bool
+	syntheticOblique bool
+}
+
+// selectFont は family の登録されたフォントから weight・style に最も近いものを選びます。
+// 選ばれたフォントが要求より細い場合や斜体でない場合は、太字や斜体の合成を指示します
+// https://www.w3.org/TR/css-fonts-4/#font-style-matching
+func (reg *fontRegistry) selectFont(family string, weight FontWeight, style FontStyle) fontSelection {
+	if weight == 0 {
+		weight = FontWeightNormal
+	}
+	if style == "" {
+		style = FontStyleNormal
+	}
+	query := fontQuery{family, weight, style}
+
+	reg.mu.RLock()
+	sel, ok := reg.selections[query]
+	faces := reg.faces[family]
+	reg.mu.RUnlock()
+	if ok {
+		return sel
+	}
+
+	sel = matchFont(faces, query)
+	reg.mu.Lock()
+	reg.selections[query] = sel
+	reg.mu.Unlock()
+	return sel
+}
+
+// matchFont は faces から query に最も近いフォントを選びます
+func matchFont(faces []*fontFace, query fontQuery) fontSelection {
+	family, weight, style := query.family, query.weight, query.style
+
+	var best *fontFace
+	for _, f := range faces {
+		if best == nil || styleRank(style, f.style) < styleRank(style, best.style) ||
+			(styleRank(style, f.style) == styleRank(style, best.style) && weightRank(weight, f.weight) < weightRank(weight, best.weight)) {
+			best = f
+		}
+	}
+
+	if best == nil {
+		return fontSelection{
+			name:             family,
+			syntheticBold:    weight >= syntheticBoldWeight,
+			syntheticOblique: style != FontStyleNormal,
+		}
+	}
+	return fontSelection{
+		face:             best,
+		name:             best.name,
+		syntheticBold:    weight >= syntheticBoldWeight && best.weight < syntheticBoldWeight,
+		syntheticOblique: style != FontStyleNormal && best.style == FontStyleNormal,
+	}
+}
+
+// styleRank は style を要求された場合の、available の優先順位を返します（小さいほど優先）
+func styleRank(style, available FontStyle) int {
+	switch {
+	case available == style:
+		return 0
+	case available == FontStyleNormal || style == FontStyleNormal && available == FontStyleItalic:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// weightRank は weight を要求された場合の、available の優先順位を返します（小さいほど優先）。
+// 400〜500 の場合は 500 までの太いもの、細いもの、500 より太いものの順、
+// 400 未満の場合は細いもの、太いものの順、500 より大きい場合は太いもの、細いものの順に、近いものを優先します
+func weightRank(weight, available FontWeight) int {
+	d := int(available - weight)
+	switch {
+	case weight >= 400 && weight <= 500:
+		switch {
+		case d >= 0 && available <= 500:
+			return d
+		case d < 0:
+			return 1000 - d
+		default:
+			return 2000 + d
+		}
+	case weight < 400:
+		if d <= 0 {
+			return -d
+		}
+		return 1000 + d
+	default:
+		if d >= 0 {
+			return d
+		}
+		return 1000 - d
+	}
+}
@@ -0,0 +1,83 @@
+package flexpdf
+
+import (
+	"testing"
+
+	"github.com/signintech/gopdf"
+)
+
+// 他の文書に登録したフォントの影響を受けないこと
+func TestFontRegistryPerDocument(t *testing.T) {
+	a := newTestPdf(t)
+	if err := a.AddTTFFontFace("", FontWeightBold, FontStyleNormal, ipaexmBytes); err != nil {
+		t.Fatal(err)
+	}
+	if sel := a.fonts.selectFont("", FontWeightBold, FontStyleNormal); sel.name != ":700:normal" || sel.syntheticBold {
+		t.Fatalf("got %+v", sel)
+	}
+
+	b := newTestPdf(t)
+	if sel := b.fonts.selectFont("", FontWeightBold, FontStyleNormal); sel.name != "" || !sel.syntheticBold {
+		t.Fatalf("got %+v", sel)
+	}
+	item := NewRowBox(NewText(NewRun("bold").SetFontWeight(FontWeightBold)))
+	if err := Draw(b, item, gopdf.PageSizeA4); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSelectFont(t *testing.T) {
+	faces := []*fontFace{
+		{name: "300", weight: 300, style: FontStyleNormal},
+		{name: "400", weight: 400, style: FontStyleNormal},
+		{name: "600", weight: 600, style: FontStyleNormal},
+		{name: "400i", weight: 400, style: FontStyleItalic},
+	}
+	tests := []struct {
+		weight           FontWeight
+		style            FontStyle
+		name             string
+		syntheticBold    bool
+		syntheticOblique bool
+	}{
+		{400, FontStyleNormal, "400", false, false},
+		{500, FontStyleNormal, "400", false, false},
+		{700, FontStyleNormal, "600", false, false},
+		{200, FontStyleNormal, "300", false, false},
+		{400, FontStyleItalic, "400i", false, false},
+		{400, FontStyleOblique, "400i", false, false},
+		{700, FontStyleItalic, "400i", true, false},
+	}
+	for _, tt := range tests {
+		sel := matchFont(faces, fontQuery{"f", tt.weight, tt.style})
+		if sel.name != tt.name || sel.syntheticBold != tt.syntheticBold || sel.syntheticOblique != tt.syntheticOblique {
+			t.Errorf("%d %s: got %+v", tt.weight, tt.style, sel)
+		}
+	}
+
+	// 登録されていないファミリーは合成で補う
+	sel := matchFont(nil, fontQuery{"f", 700, FontStyleItalic})
+	if sel.face != nil || sel.name != "f" || !sel.syntheticBold || !sel.syntheticOblique {
+		t.Errorf("got %+v", sel)
+	}
+}
+
+func TestWeightRank(t *testing.T) {
+	// https://www.w3.org/TR/css-fonts-4/#font-style-matching の例に基づく優先順位
+	tests := []struct {
+		weight FontWeight
+		order  []FontWeight
+	}{
+		{400, []FontWeight{400, 500, 300, 100, 600, 900}},
+		{500, []FontWeight{500, 400, 300, 600, 900}},
+		{300, []FontWeight{300, 200, 100, 400, 900}},
+		{700, []FontWeight{700, 900, 600, 400, 100}},
+	}
+	for _, tt := range tests {
+		for i := 1; i < len(tt.order); i++ {
+			if weightRank(tt.weight, tt.order[i-1]) >= weightRank(tt.weight, tt.order[i]) {
+				t.Errorf("%d: %d should precede %d", tt.weight, tt.order[i-1], tt.order[i])
+			}
+		}
+	}
+}
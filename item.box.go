@@ -2,19 +2,20 @@ package flexpdf
 
 import (
 	"math"
-
-	"github.com/signintech/gopdf"
 )
 
-// FlexBasis は持たず、 Width/Heightでサイズが指定してあればそのサイズ（basis=auto同等）
-// Width/Heightが指定してなければ子要素のサイズ(basis=content)となる
+// Box はアイテムをフレックスボックスのルールに従って配置するエレメントです
 type Box struct {
 	// 共通フィールド
 	flexItemCommon[*Box]
 
 	Direction      Direction
+	FlexWrap       FlexWrap
 	JustifyContent JustifyContent
 	AlignItems     AlignItems
+	AlignContent   AlignContent
+	RowGap         float64 // 行（横方向のフレックスライン、または縦方向に並ぶアイテム）の間隔
+	ColumnGap      float64 // 列（縦方向のフレックスライン、または横方向に並ぶアイテム）の間隔
 	Items          []FlexItem
 }
 
@@ -27,9 +28,11 @@ func NewRowBox(items ...FlexItem) *Box {
 
 func NewBox(dir Direction, items ...FlexItem) *Box {
 	b := &Box{
-		Direction:  dir,
-		Items:      items,
-		AlignItems: AlignItemsStretch,
+		Direction:    dir,
+		FlexWrap:     FlexWrapNoWrap,
+		Items:        items,
+		AlignItems:   AlignItemsStretch,
+		AlignContent: AlignContentStretch,
 	}
 	b.flexItemCommon.init(b)
 	return b
@@ -46,73 +49,216 @@ func (b *Box) SetAlignItems(aa AlignItems) *Box {
 	b.AlignItems = aa
 	return b
 }
-func (b *Box) drawContent(pdf *gopdf.GoPdf, r rect) (err error) {
+func (b *Box) SetAlignContent(ac AlignContent) *Box {
+	b.AlignContent = ac
+	return b
+}
+
+// SetGap は RowGap と ColumnGap を同じ値に設定します
+func (b *Box) SetGap(gap float64) *Box {
+	b.RowGap = gap
+	b.ColumnGap = gap
+	return b
+}
+func (b *Box) SetRowGap(gap float64) *Box {
+	b.RowGap = gap
+	return b
+}
+func (b *Box) SetColumnGap(gap float64) *Box {
+	b.ColumnGap = gap
+	return b
+}
+
+// gaps は主軸方向と交差軸方向の間隔を返します
+func (b *Box) gaps() (mainAxisGap, counterAxisGap float64) {
+	if b.Direction.mainAxis() == horizontal {
+		return b.ColumnGap, b.RowGap
+	}
+	return b.RowGap, b.ColumnGap
+}
+func (b *Box) SetFlexWrap(fw FlexWrap) *Box {
+	b.FlexWrap = fw
+	return b
+}
+
+// flowItems はフレックスレイアウトの対象となる（絶対配置でない）アイテムを Order の順に返します
+func (b *Box) flowItems() []FlexItem {
+	items, _ := partitionItems(b.Items)
+	return items
+}
+
+// getAbsoluteItems は絶対配置のアイテムを Order の順に返します
+func (b *Box) getAbsoluteItems() []FlexItem {
+	_, items := partitionItems(b.Items)
+	return items
+}
+
+func (b *Box) drawContent(pdf *Document, r rect, cb containingBlock, clip *rect) (err error) {
 	defer wrap(&err, "box.drawContent")
 
 	mainAxis := b.Direction.mainAxis()
 	counterAxis := !mainAxis
 
-	// 子孫
-	itemRect := r
-	prefSizes := make([]size, len(b.Items))
+	lines, err := b.layoutLines(pdf, cb, true)
+	if err != nil {
+		return err
+	}
 
-	var spacing float64
-	{
-		var growing, growTotal float64
-		mainAxisRemains := r.getLength(mainAxis)
+	// 折り返さない場合、フレックスラインはコンテナのサイズいっぱいとなる
+	if !b.isWrap() {
+		lines[0].crossSize = r.getSize(counterAxis)
+	}
 
-		// 1パス目は自然なサイズ
-		for i, item := range b.Items {
-			ps, err := item.getPreferredSize(pdf, size{w: r.w, h: r.h})
-			if err != nil {
-				return err
-			}
-			growTotal += item.getFlexGrow()
-			prefSizes[i] = ps
-			mainAxisRemains -= ps.get(mainAxis)
+	// フレックスラインの配置
+	offset, between := b.alignLines(lines, r.getSize(counterAxis))
+
+	// wrap-reverse ではフレックスラインを交差軸の終端から並べる
+	lineRect := r
+	if b.FlexWrap == FlexWrapWrapReverse {
+		lineRect = lineRect.updatePos(counterAxis, func(v float64) float64 {
+			return v + r.getSize(counterAxis) - offset
+		})
+	} else {
+		lineRect = lineRect.updatePos(counterAxis, func(v float64) float64 {
+			return v + offset
+		})
+	}
+
+	for _, line := range lines {
+		lineRect = lineRect.setSize(counterAxis, line.crossSize)
+		if b.FlexWrap == FlexWrapWrapReverse {
+			lineRect = lineRect.updatePos(counterAxis, func(v float64) float64 {
+				return v - line.crossSize
+			})
 		}
 
-		if mainAxisRemains < 0 {
-			mainAxisRemains = 0
+		if err := b.drawLine(pdf, line, cb, lineRect, clip); err != nil {
+			return err
 		}
-		if growTotal >= 1 {
-			growing = mainAxisRemains
-			spacing = 0
+
+		if b.FlexWrap == FlexWrapWrapReverse {
+			lineRect = lineRect.updatePos(counterAxis, func(v float64) float64 {
+				return v - between
+			})
 		} else {
-			growing = mainAxisRemains * growTotal
-			spacing = mainAxisRemains - growing
+			lineRect = lineRect.updatePos(counterAxis, func(v float64) float64 {
+				return v + line.crossSize + between
+			})
 		}
+	}
 
-		// log.Println(mainAxisRemains, growTotal, growing, spacing)
+	return nil
+}
 
-		// 2パス目はグロー・シュリンクを考慮したサイズ
-		for i, item := range b.Items {
-			ps := prefSizes[i]
+// alignLines は AlignContent に従ってフレックスライン間で交差軸の余白を分配します。
+// 最初のフレックスラインまでの距離と、フレックスライン同士の間隔（RowGap/ColumnGap を含む）を返します。
+// AlignContentStretch の場合は余白を各フレックスラインの交差軸サイズに加算します。
+func (b *Box) alignLines(lines []*flexLine, crossAxisLength float64) (offset, between float64) {
+	_, gap := b.gaps()
 
-			// グロー
-			if growTotal != 0 {
-				ps = ps.update(mainAxis, func(f float64) float64 {
-					return f + growing*item.getFlexGrow()/growTotal
-				})
+	free := crossAxisLength - gap*float64(len(lines)-1)
+	for _, line := range lines {
+		free -= line.crossSize
+	}
+
+	switch b.AlignContent {
+	case AlignContentFlexEnd:
+		offset = free
+	case AlignContentCenter:
+		offset = free / 2
+	case AlignContentSpaceBetween:
+		if free > 0 && len(lines) >= 2 {
+			between = free / float64(len(lines)-1)
+		}
+	case AlignContentSpaceAround:
+		if free < 0 {
+			offset = free / 2
+		} else {
+			offset = free / float64(len(lines)*2)
+			between = free / float64(len(lines))
+		}
+	case AlignContentStretch:
+		if free > 0 {
+			for _, line := range lines {
+				line.crossSize += free / float64(len(lines))
 			}
+		}
+	}
 
-			ps_, err := item.getPreferredSize(pdf, ps) // グロー・シュリンクしたサイズ
-			if err != nil {
-				return err
+	return offset, between + gap
+}
+
+// crossAxisAlign はアイテムの交差軸の配置を返します。
+// wrap-reverse では交差軸の始端と終端が入れ替わります。
+// ベースライン揃えは横方向の Box でのみ有効で、縦方向の Box では flex-start として扱います。
+func (b *Box) crossAxisAlign(item FlexItem) AlignItems {
+	align := item.getAlignSelf().resolve(b.AlignItems)
+	if align == AlignItemsBaseline && b.Direction.mainAxis() != horizontal {
+		align = AlignItemsFlexStart
+	}
+	if b.FlexWrap == FlexWrapWrapReverse {
+		switch align {
+		case AlignItemsFlexStart:
+			return AlignItemsFlexEnd
+		case AlignItemsFlexEnd:
+			return AlignItemsFlexStart
+		}
+	}
+	return align
+}
+
+// mainAxisAutoMargins はアイテムの主軸の始端・終端のマージンが auto かどうかを返します
+func (b *Box) mainAxisAutoMargins(item FlexItem) (start, end bool) {
+	m := item.getAutoMargins()
+	if b.Direction.mainAxis() == horizontal {
+		start, end = m.Left, m.Right
+	} else {
+		start, end = m.Top, m.Bottom
+	}
+	if b.Direction.isReverse() {
+		return end, start
+	}
+	return start, end
+}
+
+// crossAxisAutoMargins はアイテムの交差軸の上端（左端）・下端（右端）のマージンが auto かどうかを返します
+func (b *Box) crossAxisAutoMargins(item FlexItem) (start, end bool) {
+	m := item.getAutoMargins()
+	if b.Direction.mainAxis() == horizontal {
+		return m.Top, m.Bottom
+	}
+	return m.Left, m.Right
+}
+
+// drawLine は1つのフレックスラインに含まれるアイテムを、与えられた矩形内に描画します。
+// cb はこの Box のコンテンツボックスのサイズ、clip はクリップ領域です
+func (b *Box) drawLine(pdf *Document, line *flexLine, cb containingBlock, r rect, clip *rect) error {
+	mainAxis := b.Direction.mainAxis()
+	counterAxis := !mainAxis
+
+	// 子孫
+	itemRect := r
+	prefSizes := line.sizes
+
+	// 主軸の余白
+	spacing := math.Max(0, r.getLength(mainAxis)-line.mainSize)
+
+	// auto マージンがあれば、主軸の余白は JustifyContent より先にそれらへ均等に分配する
+	var autoMargin float64
+	{
+		autoMargins := 0
+		for _, item := range line.items {
+			start, end := b.mainAxisAutoMargins(item)
+			if start {
+				autoMargins++
 			}
-			{
-				// TODO ここの仕様を明確にする
-				// なぜmainAxisで分岐している？
-				// なぜMaxしている？
-				// グローの再計算は不要？
-				if mainAxis == horizontal {
-					ps.w = math.Max(ps.w, ps_.w)
-				} else {
-					ps.h = math.Max(ps.h, ps_.h)
-				}
+			if end {
+				autoMargins++
 			}
-
-			prefSizes[i] = ps
+		}
+		if autoMargins != 0 {
+			autoMargin = spacing / float64(autoMargins)
+			spacing = 0
 		}
 	}
 
@@ -128,60 +274,210 @@ func (b *Box) drawContent(pdf *gopdf.GoPdf, r rect) (err error) {
 		})
 	case JustifyContentSpaceAround:
 		itemRect = itemRect.updatePos(mainAxis, func(v float64) float64 {
-			return v + spacing/float64(len(b.Items)*2)
+			return v + spacing/float64(len(line.items)*2)
+		})
+	case JustifyContentSpaceEvenly:
+		itemRect = itemRect.updatePos(mainAxis, func(v float64) float64 {
+			return v + spacing/float64(len(line.items)+1)
 		})
 	}
 
-	for i, item := range b.Items {
+	for i, item := range line.items {
 		ps := prefSizes[i]
 		itemRect.w = ps.w
 		itemRect.h = ps.h
 
-		// ストレッチ
-		if b.AlignItems == AlignItemsStretch {
-			itemRect = itemRect.setSize(counterAxis, r.getSize(counterAxis))
+		autoMarginStart, autoMarginEnd := b.mainAxisAutoMargins(item)
+		if autoMarginStart {
+			itemRect = itemRect.updatePos(mainAxis, func(v float64) float64 {
+				return v + autoMargin
+			})
+		}
+
+		// 交差軸の配置
+		// 交差軸の auto マージンは AlignSelf より優先される
+		crossAxisRemains := r.getSize(counterAxis) - ps.get(counterAxis)
+		align := b.crossAxisAlign(item)
+		if start, end := b.crossAxisAutoMargins(item); start || end {
+			switch {
+			case crossAxisRemains < 0 || !start:
+				align = AlignItemsFlexStart
+			case end:
+				align = AlignItemsCenter
+			default:
+				align = AlignItemsFlexEnd
+			}
+		}
+		switch align {
+		case AlignItemsFlexStart:
+			itemRect = itemRect.setPos(counterAxis, r.getPos(counterAxis))
+		case AlignItemsFlexEnd:
+			itemRect = itemRect.setPos(counterAxis, r.getPos(counterAxis)+crossAxisRemains)
+		case AlignItemsCenter:
+			itemRect = itemRect.setPos(counterAxis, r.getPos(counterAxis)+crossAxisRemains/2)
+		case AlignItemsStretch:
+			itemRect = itemRect.setPos(counterAxis, r.getPos(counterAxis))
+			if item.isAutoSize(counterAxis) {
+				min, max := item.getSizeLimits(cb, counterAxis)
+				itemRect = itemRect.setSize(counterAxis, math.Max(math.Min(r.getSize(counterAxis), max), min))
+			}
+		case AlignItemsBaseline:
+			baseline, err := item.getBaseline(pdf, cb, ps)
+			if err != nil {
+				return err
+			}
+			itemRect = itemRect.setPos(counterAxis, r.getPos(counterAxis)+line.baseline-baseline)
+		}
+
+		// 逆方向の場合は主軸の始端と終端を入れ替える
+		drawRect := itemRect
+		if b.Direction.isReverse() {
+			drawRect = drawRect.setPos(mainAxis, 2*r.getPos(mainAxis)+r.getSize(mainAxis)-itemRect.getPos(mainAxis)-itemRect.getSize(mainAxis))
 		}
 
 		// 描画
-		if err := item.draw(pdf, itemRect); err != nil {
+		if err := item.draw(pdf, cb, drawRect, clip); err != nil {
 			return err
 		}
 
 		// アイテム間の余白
 		itemRect = itemRect.updatePos(mainAxis, func(v float64) float64 {
-			return v + ps.get(mainAxis)
+			return v + ps.get(mainAxis) + line.gap
 		})
+		if autoMarginEnd {
+			itemRect = itemRect.updatePos(mainAxis, func(v float64) float64 {
+				return v + autoMargin
+			})
+		}
 		switch b.JustifyContent {
 		case JustifyContentSpaceBetween:
 			itemRect = itemRect.updatePos(mainAxis, func(v float64) float64 {
-				return v + spacing/float64(len(b.Items)-1)
+				return v + spacing/float64(len(line.items)-1)
 			})
 		case JustifyContentSpaceAround:
 			itemRect = itemRect.updatePos(mainAxis, func(v float64) float64 {
-				return v + spacing/float64(len(b.Items))
+				return v + spacing/float64(len(line.items))
+			})
+		case JustifyContentSpaceEvenly:
+			itemRect = itemRect.updatePos(mainAxis, func(v float64) float64 {
+				return v + spacing/float64(len(line.items)+1)
 			})
 		}
 	}
 
 	return nil
 }
-func (b *Box) getContentSize(pdf *gopdf.GoPdf, contentBoxMax size) (size, error) {
+func (b *Box) getContentSize(pdf *Document, contentBoxMax containingBlock) (size, error) {
+	lines, err := b.layoutLines(pdf, contentBoxMax, false)
+	if err != nil {
+		return size{}, err
+	}
+
+	mainAxis := b.Direction.mainAxis()
+	counterAxis := !mainAxis
+
+	_, counterAxisGap := b.gaps()
 
 	cs := size{}
-	for _, item := range b.Items {
-		ips, err := item.getPreferredSize(pdf, contentBoxMax)
+	for i, line := range lines {
+		cs = cs.update(mainAxis, func(ov float64) float64 {
+			return math.Max(ov, line.mainSize)
+		})
+		if i != 0 {
+			cs = cs.add(counterAxis, counterAxisGap)
+		}
+		cs = cs.add(counterAxis, line.crossSize)
+	}
+
+	return cs, nil
+}
+
+// getContentBaseline は最初のフレックスラインのベースラインの位置を返します。
+// ベースライン揃えのアイテムがなければ、最初のアイテムのベースラインを使用します
+func (b *Box) getContentBaseline(pdf *Document, contentBox containingBlock) (float64, error) {
+	lines, err := b.layoutLines(pdf, contentBox, false)
+	if err != nil {
+		return 0, err
+	}
+
+	line := lines[0]
+	switch {
+	case line.hasBaseline:
+		return line.baseline, nil
+	case len(line.items) != 0:
+		return line.items[0].getBaseline(pdf, contentBox, line.sizes[0])
+	default:
+		return contentBox.h, nil
+	}
+}
+
+func (b *Box) getFontSize(fontSize float64) float64 {
+	return fontSize
+}
+
+// clone は Items を差し替えた Box の複製を返します
+func (b *Box) clone(items []FlexItem) *Box {
+	c := *b
+	c.self = &c
+	c.Items = items
+	return &c
+}
+
+// splitContent は縦方向の Box を、子要素の境界（または子要素の内部）でページに収まる部分とそれ以外に分割します。
+// 横方向の Box と、逆方向や折り返しを行う Box は分割しません。
+// 絶対配置のアイテムは前半部分に含めます。
+func (b *Box) splitContent(pdf *Document, contentBoxMax containingBlock, pageTop bool) (head, tail FlexItem, err error) {
+	defer wrap(&err, "box.splitContent")
+
+	if b.Direction != DirectionColumn || b.isWrap() {
+		if pageTop {
+			return b, nil, nil
+		}
+		return nil, b, nil
+	}
+
+	items := b.flowItems()
+	remains := contentBoxMax.h
+	for i, item := range items {
+		if i != 0 {
+			remains -= b.RowGap
+		}
+
+		ps, err := item.getPreferredSize(pdf, contentBoxMax, contentBoxMax.size)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ps.h <= remains {
+			remains -= ps.h
+			continue
+		}
+
+		itemHead, itemTail, err := item.split(pdf, contentBoxMax, size{w: contentBoxMax.w, h: remains}, pageTop && i == 0)
 		if err != nil {
-			return size{}, err
+			return nil, nil, err
 		}
 
-		mainAxis := b.Direction.mainAxis()
-		counterAxis := !mainAxis
+		headItems := append([]FlexItem{}, items[:i]...)
+		if itemHead != nil {
+			headItems = append(headItems, itemHead)
+		}
+		tailItems := []FlexItem{}
+		if itemTail != nil {
+			tailItems = append(tailItems, itemTail)
+		}
+		tailItems = append(tailItems, items[i+1:]...)
 
-		cs = cs.add(mainAxis, ips.get(mainAxis))
-		cs = cs.update(counterAxis, func(ov float64) float64 {
-			return math.Max(ov, ips.get(counterAxis))
-		})
+		switch {
+		case len(headItems) == 0:
+			return nil, b, nil
+		case len(tailItems) == 0:
+			return b, nil, nil
+		default:
+			// 絶対配置のアイテムは最初の断片にのみ含める
+			headItems = append(headItems, b.getAbsoluteItems()...)
+			return b.clone(headItems), b.clone(tailItems), nil
+		}
 	}
 
-	return cs, nil
+	return b, nil, nil
 }
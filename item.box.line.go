@@ -0,0 +1,248 @@
+package flexpdf
+
+import (
+	"math"
+)
+
+// flexLine は折り返しによって生じるフレックスラインを表します
+type flexLine struct {
+	items     []FlexItem
+	sizes     []size  // 各アイテムのマージンボックスのサイズ
+	gap       float64 // アイテム間の間隔
+	mainSize  float64 // アイテム間の間隔を含む主軸のサイズ
+	crossSize float64
+
+	// ベースライン揃えのアイテムがある場合、ラインの上端からベースラインまでの距離と
+	// ベースラインからアイテムの下端までの距離の最大値
+	hasBaseline bool
+	baseline    float64
+	descent     float64
+}
+
+func (l *flexLine) add(item FlexItem, ps size, mainAxis axis) {
+	if len(l.items) != 0 {
+		l.mainSize += l.gap
+	}
+	l.items = append(l.items, item)
+	l.sizes = append(l.sizes, ps)
+	l.mainSize += ps.get(mainAxis)
+	l.crossSize = math.Max(l.crossSize, ps.get(!mainAxis))
+}
+
+// addBaseline はベースライン揃えのアイテムを追加します
+func (l *flexLine) addBaseline(item FlexItem, ps size, baseline float64) {
+	l.add(item, ps, horizontal)
+	l.hasBaseline = true
+	l.baseline = math.Max(l.baseline, baseline)
+	l.descent = math.Max(l.descent, ps.h-baseline)
+	l.crossSize = math.Max(l.crossSize, l.baseline+l.descent)
+}
+
+// isWrap はアイテムを複数のフレックスラインに折り返すかどうかを返します
+func (b *Box) isWrap() bool {
+	return b.FlexWrap == FlexWrapWrap || b.FlexWrap == FlexWrapWrapReverse
+}
+
+// addToLine はアイテムをその交差軸の配置に応じてフレックスラインに追加します
+func (b *Box) addToLine(pdf *Document, line *flexLine, item FlexItem, cb containingBlock, ps size) error {
+	if b.crossAxisAlign(item) == AlignItemsBaseline {
+		baseline, err := item.getBaseline(pdf, cb, ps)
+		if err != nil {
+			return err
+		}
+		line.addBaseline(item, ps, baseline)
+	} else {
+		line.add(item, ps, b.Direction.mainAxis())
+	}
+	return nil
+}
+
+// collectLines はアイテムのフレックスベースサイズを求め、フレックスラインに分割します
+// 折り返さない場合、フレックスラインは常に1つです
+func (b *Box) collectLines(pdf *Document, contentBoxMax containingBlock) ([]*flexLine, error) {
+	mainAxis := b.Direction.mainAxis()
+	mainAxisLimit := contentBoxMax.get(mainAxis)
+	mainAxisGap, _ := b.gaps()
+
+	lines := []*flexLine{{gap: mainAxisGap}}
+	for _, item := range b.flowItems() {
+		ps, err := item.getFlexBaseSize(pdf, contentBoxMax, contentBoxMax.size, mainAxis)
+		if err != nil {
+			return nil, err
+		}
+		// 縦方向のボックスでは、幅を stretch するアイテムの AspectRatio は広げた幅から高さを求める
+		if mainAxis == vertical && item.hasAutoAspectRatio() && b.crossAxisAlign(item) == AlignItemsStretch {
+			ps, err = item.getDefiniteSize(pdf, contentBoxMax, contentBoxMax.size, horizontal)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		line := lines[len(lines)-1]
+		if b.isWrap() && len(line.items) != 0 && line.mainSize+line.gap+ps.get(mainAxis) > mainAxisLimit {
+			line = &flexLine{gap: mainAxisGap}
+			lines = append(lines, line)
+		}
+		if err := b.addToLine(pdf, line, item, contentBoxMax, ps); err != nil {
+			return nil, err
+		}
+	}
+	return lines, nil
+}
+
+// gapTotal はアイテム間の間隔の合計を返します
+func (l *flexLine) gapTotal() float64 {
+	if len(l.items) == 0 {
+		return 0
+	}
+	return l.gap * float64(len(l.items)-1)
+}
+
+// layoutLines はフレックスラインに分割し、各ラインのアイテムのサイズを確定します。
+// grow が false の場合、余白をアイテムに分配せず、あふれた場合のシュリンクのみ行います
+func (b *Box) layoutLines(pdf *Document, contentBox containingBlock, grow bool) ([]*flexLine, error) {
+	lines, err := b.collectLines(pdf, contentBox)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, line := range lines {
+		lines[i], err = b.resolveFlexibleLengths(pdf, line, contentBox, grow)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return lines, nil
+}
+
+// resolveFlexibleLengths はグロー・シュリンクによってアイテムの主軸サイズを確定し、
+// そのサイズで交差軸のサイズを求め直したフレックスラインを返します
+// https://www.w3.org/TR/css-flexbox/#resolve-flexible-lengths
+func (b *Box) resolveFlexibleLengths(pdf *Document, line *flexLine, contentBox containingBlock, grow bool) (*flexLine, error) {
+	mainAxis := b.Direction.mainAxis()
+	counterAxis := !mainAxis
+	available := contentBox.get(mainAxis) - line.gapTotal()
+
+	n := len(line.items)
+	bases := make([]float64, n)
+	targets := make([]float64, n)
+	mins := make([]float64, n)
+	maxs := make([]float64, n)
+	frozen := make([]bool, n)
+
+	// 仮の主軸サイズ（フレックスベースサイズを最小・最大サイズの範囲に収めたもの）
+	var hypotheticalTotal float64
+	for i, item := range line.items {
+		bases[i] = line.sizes[i].get(mainAxis)
+		mins[i], maxs[i] = item.getSizeLimits(contentBox, mainAxis)
+		if mins[i] < 0 {
+			// 自動的な最小サイズ
+			ms, err := item.getMinSize(pdf, contentBox, contentBox.set(mainAxis, 0))
+			if err != nil {
+				return nil, err
+			}
+			mins[i] = ms.get(mainAxis)
+		}
+		targets[i] = math.Max(math.Min(bases[i], maxs[i]), mins[i])
+		hypotheticalTotal += targets[i]
+	}
+
+	growing := hypotheticalTotal < available
+	factor := func(i int) float64 {
+		if growing {
+			return line.items[i].getFlexGrow()
+		}
+		return line.items[i].getFlexShrink() * bases[i]
+	}
+
+	// 伸縮しないアイテムを固定する
+	for i := range line.items {
+		switch {
+		case factor(i) <= 0, growing && !grow:
+			frozen[i] = true
+		case growing && bases[i] > targets[i], !growing && bases[i] < targets[i]:
+			frozen[i] = true
+		}
+	}
+
+	initialFreeSpace := available
+	for i := range line.items {
+		if frozen[i] {
+			initialFreeSpace -= targets[i]
+		} else {
+			initialFreeSpace -= bases[i]
+		}
+	}
+
+	for {
+		freeSpace := available
+		var factorTotal float64
+		for i := range line.items {
+			if frozen[i] {
+				freeSpace -= targets[i]
+			} else {
+				freeSpace -= bases[i]
+				factorTotal += factor(i)
+			}
+		}
+		if factorTotal == 0 {
+			break
+		}
+
+		// 伸長係数の合計が1未満の場合、余白のその割合だけを分配する
+		if growing && factorTotal < 1 && initialFreeSpace*factorTotal < freeSpace {
+			freeSpace = initialFreeSpace * factorTotal
+		}
+
+		// 分配し、最小・最大サイズの違反を調べる
+		var violation float64
+		clamped := make([]float64, n)
+		for i := range line.items {
+			if frozen[i] {
+				continue
+			}
+			target := bases[i] + freeSpace*factor(i)/factorTotal
+			clamped[i] = math.Max(math.Min(target, maxs[i]), mins[i])
+			violation += clamped[i] - target
+			targets[i] = target
+		}
+
+		// 違反がなければ全て固定し、最小サイズの違反が多ければ最小サイズの違反を、
+		// 最大サイズの違反が多ければ最大サイズの違反を固定して繰り返す
+		done := true
+		for i := range line.items {
+			if frozen[i] {
+				continue
+			}
+			switch {
+			case violation == 0,
+				violation > 0 && clamped[i] > targets[i],
+				violation < 0 && clamped[i] < targets[i]:
+				frozen[i] = true
+				targets[i] = clamped[i]
+			default:
+				done = false
+			}
+		}
+		if done {
+			break
+		}
+	}
+
+	resolved := &flexLine{gap: line.gap}
+	for i, item := range line.items {
+		ps := line.sizes[i]
+		if targets[i] != ps.get(mainAxis) {
+			// 確定した主軸サイズで交差軸のサイズを求め直す（テキストの折り返しなど）
+			ps_, err := item.getDefiniteSize(pdf, contentBox, contentBox.set(mainAxis, targets[i]), mainAxis)
+			if err != nil {
+				return nil, err
+			}
+			ps = ps.set(mainAxis, targets[i]).set(counterAxis, ps_.get(counterAxis))
+		}
+		if err := b.addToLine(pdf, resolved, item, contentBox, ps); err != nil {
+			return nil, err
+		}
+	}
+	return resolved, nil
+}
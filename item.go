@@ -3,60 +3,281 @@ package flexpdf
 import (
 	"image/color"
 	"log"
-
-	"github.com/signintech/gopdf"
+	"math"
+	"sort"
 )
 
 var (
 	_ flexItemContent = &Box{}
+	_ flexItemContent = &Grid{}
+	_ flexItemContent = &Table{}
 	_ flexItemContent = &Text{}
 )
 
 // flexItemContent は
 type flexItemContent interface {
 	FlexItem
-	drawContent(*gopdf.GoPdf, rect) error
-	getContentSize(pdf *gopdf.GoPdf, contentBoxMax size) (size, error)
+	// drawContent は内容を r に描画します。cb は子アイテムにとっての包含ブロック（コンテンツボックス）です
+	drawContent(pdf *Document, r rect, cb containingBlock, clip *rect) error
+	getContentSize(pdf *Document, contentBoxMax containingBlock) (size, error)
+	getContentBaseline(pdf *Document, contentBox containingBlock) (float64, error)
+	splitContent(pdf *Document, contentBoxMax containingBlock, pageTop bool) (head, tail FlexItem, err error)
+	// getFontSize は em の基準となるフォントサイズを返します。fontSize はこのアイテムのフォントサイズです
+	getFontSize(fontSize float64) float64
+	// getAbsoluteItems は絶対配置の子アイテムを返します
+	getAbsoluteItems() []FlexItem
 }
 
 type flexItemCommon[T flexItemContent] struct {
 	self            T
-	Width           float64
-	Height          float64
+	Width           Length
+	Height          Length
+	MinWidth        Length
+	MaxWidth        Length
+	MinHeight       Length
+	MaxHeight       Length
 	FlexGrow        float64
 	FlexShrink      float64
+	FlexBasis       FlexBasis
+	AlignSelf       AlignSelf
+	AspectRatio     float64 // 幅と高さの比（幅÷高さ）。0 は未指定を表します
+	Order           int     // Box 内での並び順。同じ値のアイテムは Items の順に並びます
+	Position        Position
+	Inset           LengthSpacing // Position が relative または absolute の場合の上下左右のオフセット
+	GridArea        GridArea      // Grid 内での位置と大きさ
+	Overflow        Overflow
+	FontSize        Length // 子アイテムや TextRun が継承するフォントサイズ。auto は親のフォントサイズを継承します
 	BackgroundColor color.Color
 	Border          Border
-	Margin          Spacing
-	Padding         Spacing
+	Margin          LengthSpacing
+	Padding         LengthSpacing
+}
+
+// style は Length で指定されたプロパティを包含ブロックに対して解決した値です。
+// 未指定(auto)のサイズは -1 となります
+type style struct {
+	width, height                            float64
+	minWidth, maxWidth, minHeight, maxHeight float64
+	margin, border, padding                  Spacing
+	fontSize                                 float64 // 子アイテムや TextRun が継承するフォントサイズ
+	autoHeight                               bool    // 高さが内容によって決まるかどうか
 }
 
 func (c *flexItemCommon[T]) getFlexGrow() float64 {
 	return c.FlexGrow
 }
+func (c *flexItemCommon[T]) getFlexShrink() float64 {
+	return c.FlexShrink
+}
+func (c *flexItemCommon[T]) getAlignSelf() AlignSelf {
+	return c.AlignSelf
+}
+func (c *flexItemCommon[T]) getOrder() int {
+	return c.Order
+}
+func (c *flexItemCommon[T]) getPosition() Position {
+	return c.Position
+}
+
+// getInset は Inset を包含ブロックに対して解決した値と、各辺が auto かどうかを返します
+func (c *flexItemCommon[T]) getInset(cb containingBlock) (Spacing, TRBL[bool]) {
+	_, em := c.fontSizes(cb)
+	inset := Spacing{
+		Top:    c.Inset.Top.resolve(cb.h, em),
+		Right:  c.Inset.Right.resolve(cb.w, em),
+		Bottom: c.Inset.Bottom.resolve(cb.h, em),
+		Left:   c.Inset.Left.resolve(cb.w, em),
+	}
+	return inset, c.Inset.autos()
+}
+func (c *flexItemCommon[T]) getGridArea() GridArea {
+	return c.GridArea
+}
+func (c *flexItemCommon[T]) getAutoMargins() TRBL[bool] {
+	return c.Margin.autos()
+}
+
+// isAutoSize は指定した軸のサイズが未指定かどうかを返します。
+// AspectRatio が指定されている場合は比を保つため、どちらの軸も未指定として扱いません（伸縮や stretch の対象になりません）
+func (c *flexItemCommon[T]) isAutoSize(a axis) bool {
+	if c.AspectRatio > 0 {
+		return false
+	}
+	if a == horizontal {
+		return c.Width.IsAuto()
+	}
+	return c.Height.IsAuto()
+}
+
+func (c *flexItemCommon[T]) hasAutoAspectRatio() bool {
+	return c.AspectRatio > 0 && c.Width.IsAuto() && c.Height.IsAuto()
+}
+
+// fontSizes は親のフォントサイズを継承したこのアイテムのフォントサイズと、em の基準となるフォントサイズを返します
+func (c *flexItemCommon[T]) fontSizes(cb containingBlock) (fontSize, em float64) {
+	fontSize = cb.fontSize
+	if !c.FontSize.IsAuto() {
+		fontSize = c.FontSize.resolve(cb.fontSize, cb.fontSize)
+	}
+	return fontSize, c.self.getFontSize(fontSize)
+}
+
+// style は包含ブロック cb に対して Length を解決します。
+// % は幅・高さに対応する包含ブロックの辺を、マージン・ボーダー・パディングでは包含ブロックの幅を基準とします。
+// 包含ブロックの高さが内容によって決まる場合、% の高さは auto として扱います
+func (c *flexItemCommon[T]) style(cb containingBlock) style {
+	fontSize, em := c.fontSizes(cb)
+	resolveHeight := func(l Length) float64 {
+		if cb.autoHeight && l.Unit == UnitPercent {
+			return -1
+		}
+		return l.resolveSize(cb.h, em)
+	}
+	s := style{
+		width:     c.Width.resolveSize(cb.w, em),
+		height:    resolveHeight(c.Height),
+		minWidth:  c.MinWidth.resolveSize(cb.w, em),
+		maxWidth:  c.MaxWidth.resolveSize(cb.w, em),
+		minHeight: resolveHeight(c.MinHeight),
+		maxHeight: resolveHeight(c.MaxHeight),
+		margin:    c.Margin.resolve(cb.w, em),
+		border:    c.Border.Width.resolve(cb.w, em),
+		padding:   c.Padding.resolve(cb.w, em),
+		fontSize:  fontSize,
+	}
+	s.autoHeight = s.height < 0 && c.AspectRatio <= 0
+	return s
+}
+
+// block はコンテンツボックスのサイズ contentBox を、子アイテムにとっての包含ブロックとして返します
+func (s *style) block(contentBox size) containingBlock {
+	return containingBlock{size: contentBox, fontSize: s.fontSize, autoHeight: s.autoHeight}
+}
+
+// spacing は Margin, Border, Padding の幅の合計を返します
+func (s *style) spacing() size {
+	ss := size{}
+	for _, space := range []Spacing{s.margin, s.border, s.padding} {
+		ss = ss.expand(space)
+	}
+	return ss
+}
+
+// contentBox はマージンボックスのサイズからコンテンツボックスのサイズを求めます
+func (s *style) contentBox(marginBox size) size {
+	return marginBox.shrink(s.margin).shrink(s.border).shrink(s.padding)
+}
+
+// getSizeLimits は指定した軸のマージンボックスのサイズの下限と上限を返します。
+// 下限が未指定(auto)の場合は -1 を、上限が未指定の場合は +Inf を返します
+func (c *flexItemCommon[T]) getSizeLimits(cb containingBlock, a axis) (min, max float64) {
+	s := c.style(cb)
+	min, max = s.minWidth, s.maxWidth
+	if a == vertical {
+		min, max = s.minHeight, s.maxHeight
+	}
+
+	space := s.spacing().get(a)
+	if min >= 0 {
+		min += space
+	}
+	if max >= 0 {
+		max += space
+	} else {
+		max = math.Inf(1)
+	}
+	return min, max
+}
+
+// clampSize はコンテンツボックスのサイズに Min/Max の制約を適用します。
+// 下限と上限が矛盾する場合は下限が優先されます
+func (s *style) clampSize(cs size) size {
+	if s.maxWidth >= 0 {
+		cs.w = math.Min(cs.w, s.maxWidth)
+	}
+	if s.minWidth >= 0 {
+		cs.w = math.Max(cs.w, s.minWidth)
+	}
+	if s.maxHeight >= 0 {
+		cs.h = math.Min(cs.h, s.maxHeight)
+	}
+	if s.minHeight >= 0 {
+		cs.h = math.Max(cs.h, s.minHeight)
+	}
+	return cs
+}
+
 func (c *flexItemCommon[T]) init(self T) {
 	c.self = self
-	c.Width = -1
-	c.Height = -1
+	c.Width = Auto
+	c.Height = Auto
+	c.MinWidth = Auto
+	c.MaxWidth = Auto
+	c.MinHeight = Auto
+	c.MaxHeight = Auto
 	c.FlexGrow = 0
 	c.FlexShrink = 1
+	c.FlexBasis = FlexBasisAuto
+	c.AlignSelf = AlignSelfAuto
+	c.Position = PositionStatic
+	c.Inset = UniformedSpacing(Auto)
+	c.Overflow = OverflowVisible
+	c.FontSize = Auto
 	c.BackgroundColor = nil
 	c.Border = UniformedBorder(nil, BorderStyleSolid, 0) // TODO None
 }
 
+// SetWidth はポイント単位で幅を設定します。負の値は auto として扱われます
 func (c *flexItemCommon[T]) SetWidth(w float64) T {
+	return c.SetWidthLength(ptOrAuto(w))
+}
+func (c *flexItemCommon[T]) SetHeight(h float64) T {
+	return c.SetHeightLength(ptOrAuto(h))
+}
+func (c *flexItemCommon[T]) SetSize(w, h float64) T {
+	return c.SetSizeLength(ptOrAuto(w), ptOrAuto(h))
+}
+func (c *flexItemCommon[T]) SetMinWidth(w float64) T {
+	return c.SetMinWidthLength(ptOrAuto(w))
+}
+func (c *flexItemCommon[T]) SetMaxWidth(w float64) T {
+	return c.SetMaxWidthLength(ptOrAuto(w))
+}
+func (c *flexItemCommon[T]) SetMinHeight(h float64) T {
+	return c.SetMinHeightLength(ptOrAuto(h))
+}
+func (c *flexItemCommon[T]) SetMaxHeight(h float64) T {
+	return c.SetMaxHeightLength(ptOrAuto(h))
+}
+func (c *flexItemCommon[T]) SetWidthLength(w Length) T {
 	c.Width = w
 	return c.self
 }
-func (c *flexItemCommon[T]) SetHeight(h float64) T {
+func (c *flexItemCommon[T]) SetHeightLength(h Length) T {
 	c.Height = h
 	return c.self
 }
-func (c *flexItemCommon[T]) SetSize(w, h float64) T {
+func (c *flexItemCommon[T]) SetSizeLength(w, h Length) T {
 	c.Width = w
 	c.Height = h
 	return c.self
 }
+func (c *flexItemCommon[T]) SetMinWidthLength(w Length) T {
+	c.MinWidth = w
+	return c.self
+}
+func (c *flexItemCommon[T]) SetMaxWidthLength(w Length) T {
+	c.MaxWidth = w
+	return c.self
+}
+func (c *flexItemCommon[T]) SetMinHeightLength(h Length) T {
+	c.MinHeight = h
+	return c.self
+}
+func (c *flexItemCommon[T]) SetMaxHeightLength(h Length) T {
+	c.MaxHeight = h
+	return c.self
+}
 func (c *flexItemCommon[T]) SetFlexGrow(g float64) T {
 	c.FlexGrow = g
 	return c.self
@@ -65,6 +286,70 @@ func (c *flexItemCommon[T]) SetFlexShrink(s float64) T {
 	c.FlexShrink = s
 	return c.self
 }
+func (c *flexItemCommon[T]) SetFlexBasis(b FlexBasis) T {
+	c.FlexBasis = b
+	return c.self
+}
+
+// SetFlex は FlexGrow, FlexShrink, FlexBasis をまとめて設定します
+func (c *flexItemCommon[T]) SetFlex(grow, shrink float64, basis FlexBasis) T {
+	c.FlexGrow = grow
+	c.FlexShrink = shrink
+	c.FlexBasis = basis
+	return c.self
+}
+func (c *flexItemCommon[T]) SetAlignSelf(as AlignSelf) T {
+	c.AlignSelf = as
+	return c.self
+}
+
+// SetAspectRatio は幅と高さの比（幅÷高さ）を設定します
+func (c *flexItemCommon[T]) SetAspectRatio(ratio float64) T {
+	c.AspectRatio = ratio
+	return c.self
+}
+func (c *flexItemCommon[T]) SetOrder(order int) T {
+	c.Order = order
+	return c.self
+}
+func (c *flexItemCommon[T]) SetPosition(p Position) T {
+	c.Position = p
+	return c.self
+}
+
+// SetInset は CSS の inset と同様に、1〜4個の値で上下左右のオフセットを設定します
+func (c *flexItemCommon[T]) SetInset(values ...Length) T {
+	c.Inset = LengthSpacing(parseTRBL(values...))
+	return c.self
+}
+
+// SetGridArea は Grid 内での位置（1始まりの行・列）と、またがる行数・列数を設定します
+func (c *flexItemCommon[T]) SetGridArea(row, column, rowSpan, columnSpan int) T {
+	c.GridArea = GridArea{Row: row, Column: column, RowSpan: rowSpan, ColumnSpan: columnSpan}
+	return c.self
+}
+
+// SetGridSpan は Grid 内で自動的に配置されるアイテムがまたがる行数・列数を設定します
+func (c *flexItemCommon[T]) SetGridSpan(rowSpan, columnSpan int) T {
+	c.GridArea.RowSpan = rowSpan
+	c.GridArea.ColumnSpan = columnSpan
+	return c.self
+}
+func (c *flexItemCommon[T]) SetOverflow(o Overflow) T {
+	c.Overflow = o
+	return c.self
+}
+
+// SetFontSize はポイント単位で子アイテムや TextRun が継承するフォントサイズを設定します。負の値は auto（親から継承）として扱われます
+func (c *flexItemCommon[T]) SetFontSize(s float64) T {
+	return c.SetFontSizeLength(ptOrAuto(s))
+}
+
+// SetFontSizeLength は Length でフォントサイズを設定します。% と em は親のフォントサイズを基準とします
+func (c *flexItemCommon[T]) SetFontSizeLength(s Length) T {
+	c.FontSize = s
+	return c.self
+}
 func (b *flexItemCommon[T]) SetBackgroundColor(c color.Color) T {
 	b.BackgroundColor = c
 	return b.self
@@ -73,33 +358,35 @@ func (c *flexItemCommon[T]) SetBorder(border Border) T {
 	c.Border = border
 	return c.self
 }
-func (*flexItemCommon[T]) parseSpacing(values ...float64) Spacing {
+
+// parseTRBL は CSS のショートハンドと同様に、1〜4個の値を上下左右に割り当てます
+func parseTRBL[V any](values ...V) TRBL[V] {
 	switch len(values) {
 	case 0:
-		return Spacing{}
+		return TRBL[V]{}
 	case 1: // TRBL
-		return Spacing{
+		return TRBL[V]{
 			Top:    values[0],
 			Right:  values[0],
 			Bottom: values[0],
 			Left:   values[0],
 		}
 	case 2: // TB | RL
-		return Spacing{
+		return TRBL[V]{
 			Top:    values[0],
 			Bottom: values[0],
 			Right:  values[1],
 			Left:   values[1],
 		}
 	case 3: // T | RL | B
-		return Spacing{
+		return TRBL[V]{
 			Top:    values[0],
 			Right:  values[1],
 			Left:   values[1],
 			Bottom: values[2],
 		}
 	default: // T | R | B | L
-		return Spacing{
+		return TRBL[V]{
 			Top:    values[0],
 			Right:  values[1],
 			Bottom: values[2],
@@ -108,56 +395,135 @@ func (*flexItemCommon[T]) parseSpacing(values ...float64) Spacing {
 	}
 }
 func (c *flexItemCommon[T]) SetMargin(values ...float64) T {
-	c.Margin = c.parseSpacing(values...)
+	return c.SetMarginLength(pts(values...)...)
+}
+
+// SetMarginLength は Length でマージンを設定します。Auto を指定したマージンは主軸・交差軸の余白を吸収します
+func (c *flexItemCommon[T]) SetMarginLength(values ...Length) T {
+	c.Margin = LengthSpacing(parseTRBL(values...))
 	return c.self
 }
 func (c *flexItemCommon[T]) SetPadding(values ...float64) T {
-	c.Padding = c.parseSpacing(values...)
+	return c.SetPaddingLength(pts(values...)...)
+}
+func (c *flexItemCommon[T]) SetPaddingLength(values ...Length) T {
+	c.Padding = LengthSpacing(parseTRBL(values...))
 	return c.self
 }
 
-func (c *flexItemCommon[T]) draw(pdf *gopdf.GoPdf, marginBox rect) (err error) {
+func (c *flexItemCommon[T]) draw(pdf *Document, cb containingBlock, marginBox rect, clip *rect) (err error) {
 	defer wrap(&err, "common.draw")
 
-	borderBox := marginBox.shrink(c.Margin)
-	paddingBox := borderBox.shrink(c.Border.Width)
-	contentBox := paddingBox.shrink(c.Padding)
+	// 相対配置のアイテムは通常の位置からずらして描画する
+	if c.Position == PositionRelative {
+		dx, dy := relativeOffset(c.getInset(cb))
+		marginBox.x += dx
+		marginBox.y += dy
+	}
+
+	s := c.style(cb)
+	borderBox := marginBox.shrink(s.margin)
+	paddingBox := borderBox.shrink(s.border)
+	contentBox := paddingBox.shrink(s.padding)
 
 	// 背景色
-	if c.BackgroundColor != nil && borderBox.w > 0 && borderBox.h > 0 {
-		if err := setColor(pdf, c.BackgroundColor); err != nil {
-			return err
+	if bg := borderBox; c.BackgroundColor != nil {
+		if clip != nil {
+			bg = bg.intersect(*clip)
 		}
-		if err := pdf.Rectangle(borderBox.x, borderBox.y, borderBox.x+borderBox.w, borderBox.y+borderBox.h, "F", 0, 0); err != nil {
-			return err
+		if bg.w > 0 && bg.h > 0 {
+			if err := setColor(pdf, c.BackgroundColor); err != nil {
+				return err
+			}
+			if err := pdf.Rectangle(bg.x, bg.y, bg.x+bg.w, bg.y+bg.h, "F", 0, 0); err != nil {
+				return err
+			}
 		}
 	}
-	if err := c.self.drawContent(pdf, contentBox); err != nil {
+
+	// OverflowHidden の場合、内容と絶対配置の子アイテムはパディングボックスの外に描画しない
+	contentClip := clip
+	if c.Overflow == OverflowHidden {
+		contentClip = intersectClip(clip, paddingBox)
+	}
+
+	if err := c.self.drawContent(pdf, contentBox, s.block(size{w: contentBox.w, h: contentBox.h}), contentClip); err != nil {
 		return err
 	}
-	if err := c.Border.draw(pdf, borderBox); err != nil {
+	if err := c.Border.draw(pdf, borderBox, s.border, clip); err != nil {
 		return err
 	}
+
+	// 絶対配置の子アイテムは最後にパディングボックスを基準に描画する
+	for _, item := range c.self.getAbsoluteItems() {
+		if err := drawAbsolute(pdf, item, paddingBox, s.fontSize, contentClip); err != nil {
+			return err
+		}
+	}
 	return nil
 }
-func (c *flexItemCommon[T]) getPreferredSize(pdf *gopdf.GoPdf, marginBoxMax size) (size, error) {
-	contentBoxMax := marginBoxMax.shrink(c.Margin).shrink(c.Border.Width).shrink(c.Padding)
+func (c *flexItemCommon[T]) getPreferredSize(pdf *Document, cb containingBlock, marginBoxMax size) (size, error) {
+	return c.preferredSize(pdf, c.style(cb), marginBoxMax)
+}
+
+// getDefiniteSize は軸 a のマージンボックスのサイズを marginBox のその軸のサイズに確定した場合の、マージンボックスのサイズを返します
+func (c *flexItemCommon[T]) getDefiniteSize(pdf *Document, cb containingBlock, marginBox size, a axis) (size, error) {
+	s := c.style(cb)
+	v := math.Max(0, marginBox.get(a)-s.spacing().get(a))
+	if a == horizontal {
+		s.width = v
+	} else {
+		s.height = v
+		s.autoHeight = false
+	}
+	return c.preferredSize(pdf, s, marginBox)
+}
+
+// preferredSize は解決済みのスタイル s で getPreferredSize を行います
+func (c *flexItemCommon[T]) preferredSize(pdf *Document, s style, marginBoxMax size) (size, error) {
+	contentBoxMax := s.contentBox(marginBoxMax)
+
+	// AspectRatio が指定されていれば、未指定の一方のサイズを他方から求める
+	// どちらも未指定の場合は、内容の幅を幅とする
+	if c.AspectRatio > 0 {
+		switch {
+		case s.width >= 0 && s.height < 0:
+			s.height = s.width / c.AspectRatio
+		case s.width < 0 && s.height >= 0:
+			s.width = s.height * c.AspectRatio
+		case s.width < 0 && s.height < 0:
+			cs, err := c.contentSize(pdf, s.block(s.clampSize(contentBoxMax)))
+			if err != nil {
+				return size{}, err
+			}
+			s.width = s.clampSize(cs).w
+			s.height = s.width / c.AspectRatio
+		}
+	}
+
+	// Width/Height が指定されていれば、内容はそのサイズの中に配置する
+	if s.width >= 0 {
+		contentBoxMax.w = s.width
+	}
+	if s.height >= 0 {
+		contentBoxMax.h = s.height
+	}
+	contentBoxMax = s.clampSize(contentBoxMax)
 
-	ps, err := c.self.getContentSize(pdf, contentBoxMax)
+	ps, err := c.contentSize(pdf, s.block(contentBoxMax))
 	if err != nil {
 		return size{}, err
 	}
 
-	// TODO もしWidthが指定されてるなら、そこからMargin, Border, Paddingを引いてから
-	// getContentSizeにwidthを渡す
-	if c.Width >= 0 {
-		ps.w = c.Width
+	if s.width >= 0 {
+		ps.w = s.width
 	}
-	if c.Height >= 0 {
-		ps.h = c.Height
+	if s.height >= 0 {
+		ps.h = s.height
 	}
+	ps = s.clampSize(ps)
 
-	for _, space := range []Spacing{c.Margin, c.Border.Width, c.Padding} {
+	for _, space := range []Spacing{s.margin, s.border, s.padding} {
 		ps = ps.expand(space)
 	}
 
@@ -167,3 +533,141 @@ func (c *flexItemCommon[T]) getPreferredSize(pdf *gopdf.GoPdf, marginBoxMax size
 
 	return ps, nil
 }
+
+// contentSizeKey は内容のサイズのキャッシュのキーです
+type contentSizeKey struct {
+	item FlexItem
+	cb   containingBlock
+}
+
+// contentSize は包含ブロック cb に対する内容のサイズを返します。
+// Draw の間は結果をアイテムと包含ブロックごとに保持し、入れ子のアイテムを繰り返し計測しないようにします
+func (c *flexItemCommon[T]) contentSize(pdf *Document, cb containingBlock) (size, error) {
+	key := contentSizeKey{item: c.self, cb: cb}
+	if s, ok := pdf.contentSizes[key]; ok {
+		return s, nil
+	}
+	s, err := c.self.getContentSize(pdf, cb)
+	if err != nil {
+		return size{}, err
+	}
+	if pdf.contentSizes != nil {
+		pdf.contentSizes[key] = s
+	}
+	return s, nil
+}
+
+// getFlexBaseSize は主軸のサイズを FlexBasis に従って求めたマージンボックスのサイズを返します
+func (c *flexItemCommon[T]) getFlexBaseSize(pdf *Document, cb containingBlock, marginBoxMax size, mainAxis axis) (size, error) {
+	ps, err := c.getPreferredSize(pdf, cb, marginBoxMax)
+	if err != nil {
+		return size{}, err
+	}
+
+	s := c.style(cb)
+	switch {
+	case c.FlexBasis == FlexBasisContent:
+		cs, err := c.contentSize(pdf, s.block(s.contentBox(marginBoxMax)))
+		if err != nil {
+			return size{}, err
+		}
+		ps = ps.set(mainAxis, cs.get(mainAxis)+s.spacing().get(mainAxis))
+	case c.FlexBasis >= 0:
+		ps = ps.set(mainAxis, float64(c.FlexBasis)+s.spacing().get(mainAxis))
+	}
+
+	// Min/Max が指定されていれば、その範囲に収める
+	min, max := c.getSizeLimits(cb, mainAxis)
+	ps = ps.update(mainAxis, func(v float64) float64 {
+		return math.Max(math.Min(v, max), min)
+	})
+
+	return ps, nil
+}
+
+// getMinSize は内容を marginBoxMax に収まるよう最大限折り返したときのサイズを返します。
+// 主軸に 0 を与えることで、それ以上縮めることのできない最小のサイズ（min-content）が得られます。
+// Width/Height や MaxWidth/MaxHeight が指定されている場合はそれより大きくなりません
+func (c *flexItemCommon[T]) getMinSize(pdf *Document, cb containingBlock, marginBoxMax size) (size, error) {
+	s := c.style(cb)
+
+	ms, err := c.contentSize(pdf, s.block(s.contentBox(marginBoxMax)))
+	if err != nil {
+		return size{}, err
+	}
+
+	if s.width >= 0 {
+		ms.w = math.Min(ms.w, s.width)
+	}
+	if s.height >= 0 {
+		ms.h = math.Min(ms.h, s.height)
+	}
+	if s.maxWidth >= 0 {
+		ms.w = math.Min(ms.w, s.maxWidth)
+	}
+	if s.maxHeight >= 0 {
+		ms.h = math.Min(ms.h, s.maxHeight)
+	}
+
+	for _, space := range []Spacing{s.margin, s.border, s.padding} {
+		ms = ms.expand(space)
+	}
+
+	return ms, nil
+}
+
+func (c *flexItemCommon[T]) getBaseline(pdf *Document, cb containingBlock, marginBox size) (float64, error) {
+	s := c.style(cb)
+
+	b, err := c.self.getContentBaseline(pdf, s.block(s.contentBox(marginBox)))
+	if err != nil {
+		return 0, err
+	}
+
+	return s.margin.Top + s.border.Top + s.padding.Top + b, nil
+}
+
+// split はこのアイテムを marginBoxMax に収まる前半部分と、残りの後半部分に分割します。
+// 全体が収まる場合は tail が nil となり、何も収まらない場合は head が nil となります。
+// pageTop はこのアイテムがページの先頭に配置されることを示し、その場合は少なくとも一部を head に含めます。
+func (c *flexItemCommon[T]) split(pdf *Document, cb containingBlock, marginBoxMax size, pageTop bool) (head, tail FlexItem, err error) {
+	defer wrap(&err, "common.split")
+
+	ps, err := c.getPreferredSize(pdf, cb, marginBoxMax)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ps.h <= marginBoxMax.h {
+		return c.self, nil, nil
+	}
+
+	// 高さが指定されているアイテムは分割しない
+	if !c.isAutoSize(vertical) {
+		if pageTop {
+			return c.self, nil, nil
+		}
+		return nil, c.self, nil
+	}
+
+	s := c.style(cb)
+	return c.self.splitContent(pdf, s.block(s.contentBox(marginBoxMax)), pageTop)
+}
+
+// partitionItems は items を Order の昇順に安定ソートし、
+// 通常のレイアウトの対象となるアイテムと絶対配置のアイテムに分けて返します
+func partitionItems(items []FlexItem) (flow, absolute []FlexItem) {
+	sorted := append([]FlexItem{}, items...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].getOrder() < sorted[j].getOrder()
+	})
+
+	flow, absolute = []FlexItem{}, []FlexItem{}
+	for _, item := range sorted {
+		if item.getPosition() == PositionAbsolute {
+			absolute = append(absolute, item)
+		} else {
+			flow = append(flow, item)
+		}
+	}
+	return flow, absolute
+}
@@ -0,0 +1,615 @@
+package flexpdf
+
+import (
+	"math"
+)
+
+// TrackSize はグリッドのトラック（行・列）のサイズです
+// https://www.w3.org/TR/css-grid-1/#track-sizing
+type TrackSize struct {
+	Min Length  // 最小サイズ。Auto の場合は内容の最小サイズ
+	Max Length  // 最大サイズ。Auto の場合は内容の最大サイズ
+	Fr  float64 // 0 より大きい場合、Max の代わりに残りのスペースをこの比率で分け合います
+}
+
+// TrackAuto は内容に合わせたサイズのトラックです
+var TrackAuto = TrackSize{Min: Auto, Max: Auto}
+
+// TrackFixed は固定サイズのトラックを返します
+func TrackFixed(l Length) TrackSize {
+	return TrackSize{Min: l, Max: l}
+}
+
+// TrackFr は残りのスペースを比率 fr で分け合うトラックを返します（minmax(auto, <fr>) に相当します）
+func TrackFr(fr float64) TrackSize {
+	return TrackSize{Min: Auto, Max: Auto, Fr: fr}
+}
+
+// TrackMinMax は minmax(min, max) に相当するトラックを返します
+func TrackMinMax(min, max Length) TrackSize {
+	return TrackSize{Min: min, Max: max}
+}
+
+// TrackMinMaxFr は minmax(min, <fr>) に相当するトラックを返します
+func TrackMinMaxFr(min Length, fr float64) TrackSize {
+	return TrackSize{Min: min, Max: Auto, Fr: fr}
+}
+
+// GridArea はグリッド内でのアイテムの位置と大きさです。
+// Row/Column は1始まりで、0 の場合は空いているセルに自動的に配置されます。
+// RowSpan/ColumnSpan が 0 の場合は 1 として扱います
+type GridArea struct {
+	Row        int
+	Column     int
+	RowSpan    int
+	ColumnSpan int
+}
+
+// Grid はアイテムを行と列からなるグリッドに配置するエレメントです。
+// 包含ブロックはグリッドのコンテンツボックスとし、ページをまたぐ場合は行の境界で分割します
+type Grid struct {
+	// 共通フィールド
+	flexItemCommon[*Grid]
+
+	Columns   []TrackSize
+	Rows      []TrackSize
+	AutoRows  TrackSize // Rows を超えて暗黙的に追加される行のサイズ
+	RowGap    float64
+	ColumnGap float64
+	Items     []FlexItem
+
+	placement    []gridCell // 分割されたグリッドで、アイテムの配置を固定するために使用します
+	columnWidths []float64  // 分割されたグリッドで、元のグリッドと列の幅を揃えるために使用します
+}
+
+func NewGrid(columns []TrackSize, items ...FlexItem) *Grid {
+	g := &Grid{
+		Columns:  columns,
+		AutoRows: TrackAuto,
+		Items:    items,
+	}
+	g.flexItemCommon.init(g)
+	return g
+}
+func (g *Grid) SetColumns(columns ...TrackSize) *Grid {
+	g.Columns = columns
+	return g
+}
+func (g *Grid) SetRows(rows ...TrackSize) *Grid {
+	g.Rows = rows
+	return g
+}
+func (g *Grid) SetAutoRows(row TrackSize) *Grid {
+	g.AutoRows = row
+	return g
+}
+
+// SetGap は RowGap と ColumnGap を同じ値に設定します
+func (g *Grid) SetGap(gap float64) *Grid {
+	g.RowGap = gap
+	g.ColumnGap = gap
+	return g
+}
+func (g *Grid) SetRowGap(gap float64) *Grid {
+	g.RowGap = gap
+	return g
+}
+func (g *Grid) SetColumnGap(gap float64) *Grid {
+	g.ColumnGap = gap
+	return g
+}
+
+// gridCell はグリッドに配置されたアイテムです。行・列は0始まりです
+type gridCell struct {
+	item                          FlexItem
+	row, column, rowSpan, colSpan int
+}
+
+// gridLayout はトラックのサイズを確定したグリッドです
+type gridLayout struct {
+	cells   []gridCell
+	columns []float64
+	rows    []float64
+}
+
+// trackContribution はアイテムがまたがるトラックに要求する最小・最大のサイズです
+type trackContribution struct {
+	start, span int
+	min, max    float64
+}
+
+// placeItems はアイテムをグリッドのセルに配置し、列数と行数を返します。
+// 位置が指定されたアイテムを先に配置し、残りのアイテムを行優先で空いているセルに配置します
+func (g *Grid) placeItems() (cells []gridCell, columns, rows int) {
+	if g.placement != nil {
+		for _, cell := range g.placement {
+			rows = maxInt(rows, cell.row+cell.rowSpan)
+		}
+		return g.placement, len(g.columnWidths), maxInt(rows, len(g.Rows))
+	}
+
+	items, _ := partitionItems(g.Items)
+
+	columns = len(g.Columns)
+	for _, item := range items {
+		area := item.getGridArea()
+		if area.Column > 0 {
+			columns = maxInt(columns, area.Column-1+maxInt(area.ColumnSpan, 1))
+		}
+	}
+	columns = maxInt(columns, 1)
+
+	occupied := map[[2]int]bool{}
+	vacant := func(row, column, rowSpan, colSpan int) bool {
+		for r := row; r < row+rowSpan; r++ {
+			for c := column; c < column+colSpan; c++ {
+				if occupied[[2]int{r, c}] {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	fits := func(row, column, rowSpan, colSpan int) bool {
+		return column+colSpan <= columns && vacant(row, column, rowSpan, colSpan)
+	}
+	place := func(item FlexItem, row, column, rowSpan, colSpan int) {
+		for r := row; r < row+rowSpan; r++ {
+			for c := column; c < column+colSpan; c++ {
+				occupied[[2]int{r, c}] = true
+			}
+		}
+		cells = append(cells, gridCell{item: item, row: row, column: column, rowSpan: rowSpan, colSpan: colSpan})
+		rows = maxInt(rows, row+rowSpan)
+	}
+
+	spans := func(area GridArea) (int, int) {
+		return maxInt(area.RowSpan, 1), minInt(maxInt(area.ColumnSpan, 1), columns)
+	}
+
+	// 行・列ともに指定されたアイテム
+	for _, item := range items {
+		area := item.getGridArea()
+		if area.Row > 0 && area.Column > 0 {
+			rowSpan, colSpan := spans(area)
+			place(item, area.Row-1, area.Column-1, rowSpan, colSpan)
+		}
+	}
+	// 行のみ指定されたアイテム。空いている列がなければ暗黙的に列を追加する
+	for _, item := range items {
+		area := item.getGridArea()
+		if area.Row > 0 && area.Column <= 0 {
+			rowSpan, colSpan := spans(area)
+			column := 0
+			for !vacant(area.Row-1, column, rowSpan, colSpan) {
+				column++
+			}
+			columns = maxInt(columns, column+colSpan)
+			place(item, area.Row-1, column, rowSpan, colSpan)
+		}
+	}
+	// 残りのアイテム
+	cursorRow, cursorColumn := 0, 0
+	for _, item := range items {
+		area := item.getGridArea()
+		if area.Row > 0 {
+			continue
+		}
+		rowSpan, colSpan := spans(area)
+		if area.Column > 0 {
+			if area.Column-1 < cursorColumn {
+				cursorRow++
+			}
+			cursorColumn = area.Column - 1
+			for !fits(cursorRow, cursorColumn, rowSpan, colSpan) {
+				cursorRow++
+			}
+		} else {
+			for !fits(cursorRow, cursorColumn, rowSpan, colSpan) {
+				cursorColumn++
+				if cursorColumn+colSpan > columns {
+					cursorRow++
+					cursorColumn = 0
+				}
+			}
+		}
+		place(item, cursorRow, cursorColumn, rowSpan, colSpan)
+		cursorColumn += colSpan
+	}
+
+	rows = maxInt(rows, len(g.Rows))
+	return cells, columns, rows
+}
+
+// trackDefs は n 個のトラックの定義を、不足分を fallback で補って返します
+func trackDefs(defs []TrackSize, n int, fallback TrackSize) []TrackSize {
+	tracks := make([]TrackSize, n)
+	for i := range tracks {
+		if i < len(defs) {
+			tracks[i] = defs[i]
+		} else {
+			tracks[i] = fallback
+		}
+	}
+	return tracks
+}
+
+// trackEpsilon はトラックへの分配で無視できる残りのスペースです
+const trackEpsilon = 1e-9
+
+// sizeTracks はアイテムの寄与に基づいてトラックのサイズを求めます。
+// base は % の基準となる長さ、available は利用可能な長さです。
+// definite が false の場合、fr のトラックは内容に合わせたサイズとなります
+// https://www.w3.org/TR/css-grid-1/#algo-track-sizing
+func sizeTracks(defs []TrackSize, contribs []trackContribution, base, em, available, gap float64, definite bool) []float64 {
+	n := len(defs)
+	bases := make([]float64, n)
+	limits := make([]float64, n)
+	flexible := make([]bool, n)
+
+	// 固定のサイズで初期化する
+	for i, d := range defs {
+		flexible[i] = d.Fr > 0 && definite
+		if !d.Min.IsAuto() {
+			bases[i] = d.Min.resolve(base, em)
+		}
+		limits[i] = -1
+		if !d.Max.IsAuto() && d.Fr <= 0 {
+			limits[i] = math.Max(d.Max.resolve(base, em), bases[i])
+		}
+	}
+
+	// 1つのトラックに収まるアイテム
+	for _, c := range contribs {
+		if c.span != 1 {
+			continue
+		}
+		if defs[c.start].Min.IsAuto() {
+			bases[c.start] = math.Max(bases[c.start], c.min)
+		}
+		if defs[c.start].Max.IsAuto() || defs[c.start].Fr > 0 {
+			limits[c.start] = math.Max(limits[c.start], c.max)
+		}
+	}
+
+	// 複数のトラックにまたがるアイテムは、不足分をサイズが auto のトラックに均等に分配する
+	for _, c := range contribs {
+		if c.span == 1 {
+			continue
+		}
+		// fr のトラックにまたがる場合は、最小サイズの不足分のみを fr のトラックに分配する
+		crossesFlexible := false
+		for i := c.start; i < c.start+c.span; i++ {
+			crossesFlexible = crossesFlexible || flexible[i]
+		}
+
+		have := gap * float64(c.span-1)
+		autos := []int{}
+		for i := c.start; i < c.start+c.span; i++ {
+			have += bases[i]
+			if defs[i].Min.IsAuto() && (!crossesFlexible || flexible[i]) {
+				autos = append(autos, i)
+			}
+		}
+		if c.min > have && len(autos) != 0 {
+			for _, i := range autos {
+				bases[i] += (c.min - have) / float64(len(autos))
+			}
+		}
+
+		if crossesFlexible {
+			continue
+		}
+		have = gap * float64(c.span-1)
+		autos = autos[:0]
+		for i := c.start; i < c.start+c.span; i++ {
+			have += math.Max(bases[i], limits[i])
+			if defs[i].Max.IsAuto() || defs[i].Fr > 0 {
+				autos = append(autos, i)
+			}
+		}
+		if c.max > have && len(autos) != 0 {
+			for _, i := range autos {
+				limits[i] = math.Max(bases[i], limits[i]) + (c.max-have)/float64(len(autos))
+			}
+		}
+	}
+	for i := range limits {
+		limits[i] = math.Max(limits[i], bases[i])
+	}
+
+	// 利用可能なスペースを、fr でないトラックに上限まで均等に分配する
+	free := available - gap*float64(maxInt(n-1, 0))
+	for i := range bases {
+		free -= bases[i]
+	}
+	if !definite {
+		free = math.Inf(1)
+	}
+	for free > trackEpsilon {
+		growable := []int{}
+		for i := range bases {
+			if !flexible[i] && bases[i] < limits[i] {
+				growable = append(growable, i)
+			}
+		}
+		if len(growable) == 0 {
+			break
+		}
+		share := free / float64(len(growable))
+		var grown float64
+		for _, i := range growable {
+			grow := math.Min(share, limits[i]-bases[i])
+			bases[i] += grow
+			grown += grow
+		}
+		free -= grown
+		// 丸め誤差で分配量が 0 になった場合に無限ループしないようにする
+		if grown <= trackEpsilon {
+			break
+		}
+	}
+
+	// 残りのスペースを fr のトラックで分け合う
+	// 最小サイズが分配されるサイズより大きいトラックは、最小サイズに固定して繰り返す
+	if free > 0 {
+		fixed := make([]bool, n)
+		for {
+			space := free
+			var frTotal float64
+			for i := range defs {
+				if flexible[i] && !fixed[i] {
+					space += bases[i]
+					frTotal += defs[i].Fr
+				}
+			}
+			if frTotal == 0 {
+				break
+			}
+			unit := space / math.Max(frTotal, 1)
+
+			done := true
+			for i := range defs {
+				if flexible[i] && !fixed[i] && bases[i] > unit*defs[i].Fr {
+					fixed[i] = true
+					done = false
+				}
+			}
+			if done {
+				for i := range defs {
+					if flexible[i] && !fixed[i] {
+						bases[i] = unit * defs[i].Fr
+					}
+				}
+				break
+			}
+		}
+	}
+
+	return bases
+}
+
+// trackOffset は start 番目のトラックの始端の位置を返します
+func trackOffset(tracks []float64, gap float64, start int) float64 {
+	o := gap * float64(start)
+	for _, t := range tracks[:start] {
+		o += t
+	}
+	return o
+}
+
+// tracksLength は start から span 個のトラックとその間隔を合わせた長さを返します
+func tracksLength(tracks []float64, gap float64, start, span int) float64 {
+	if span == 0 {
+		return 0
+	}
+	l := gap * float64(span-1)
+	for _, t := range tracks[start : start+span] {
+		l += t
+	}
+	return l
+}
+
+// layout はアイテムを配置し、トラックのサイズを確定します。
+// definite が false の場合、行の高さは内容に合わせたサイズとなります
+func (g *Grid) layout(pdf *Document, contentBox containingBlock, definite bool) (*gridLayout, error) {
+	cells, columnCount, rowCount := g.placeItems()
+	em := contentBox.fontSize
+
+	// 列の幅
+	columns := g.columnWidths
+	if columns == nil {
+		colContribs := []trackContribution{}
+		for _, cell := range cells {
+			ms, err := cell.item.getMinSize(pdf, contentBox, contentBox.set(horizontal, 0))
+			if err != nil {
+				return nil, err
+			}
+			ps, err := cell.item.getPreferredSize(pdf, contentBox, contentBox.size)
+			if err != nil {
+				return nil, err
+			}
+			if !cell.item.isAutoSize(horizontal) {
+				ms.w = ps.w
+			}
+			colContribs = append(colContribs, trackContribution{start: cell.column, span: cell.colSpan, min: ms.w, max: ps.w})
+		}
+		columns = sizeTracks(trackDefs(g.Columns, columnCount, TrackAuto), colContribs, contentBox.w, em, contentBox.w, g.ColumnGap, true)
+	}
+
+	// 行の高さ（確定した列の幅で内容の高さを求める）
+	rowContribs := []trackContribution{}
+	for _, cell := range cells {
+		w := tracksLength(columns, g.ColumnGap, cell.column, cell.colSpan)
+		ps, err := cell.item.getPreferredSize(pdf, contentBox, size{w: w, h: contentBox.h})
+		if err != nil {
+			return nil, err
+		}
+		rowContribs = append(rowContribs, trackContribution{start: cell.row, span: cell.rowSpan, min: ps.h, max: ps.h})
+	}
+	rows := sizeTracks(trackDefs(g.Rows, rowCount, g.AutoRows), rowContribs, contentBox.h, em, contentBox.h, g.RowGap, definite)
+
+	return &gridLayout{cells: cells, columns: columns, rows: rows}, nil
+}
+
+// area はセルの領域を、グリッドのコンテンツボックスの左上を原点として返します
+func (g *Grid) area(l *gridLayout, cell gridCell) rect {
+	return rect{
+		x: trackOffset(l.columns, g.ColumnGap, cell.column),
+		y: trackOffset(l.rows, g.RowGap, cell.row),
+		w: tracksLength(l.columns, g.ColumnGap, cell.column, cell.colSpan),
+		h: tracksLength(l.rows, g.RowGap, cell.row, cell.rowSpan),
+	}
+}
+
+func (g *Grid) drawContent(pdf *Document, r rect, cb containingBlock, clip *rect) (err error) {
+	defer wrap(&err, "grid.drawContent")
+
+	l, err := g.layout(pdf, cb, true)
+	if err != nil {
+		return err
+	}
+
+	for _, cell := range l.cells {
+		area := g.area(l, cell)
+		area.x += r.x
+		area.y += r.y
+
+		// サイズが未指定のアイテムはセルいっぱいに広げ、それ以外はセルの左上に配置する
+		ps, err := cell.item.getPreferredSize(pdf, cb, size{w: area.w, h: area.h})
+		if err != nil {
+			return err
+		}
+		itemRect := rect{x: area.x, y: area.y, w: ps.w, h: ps.h}
+		for _, a := range []axis{horizontal, vertical} {
+			if cell.item.isAutoSize(a) {
+				min, max := cell.item.getSizeLimits(cb, a)
+				itemRect = itemRect.setSize(a, math.Max(math.Min(area.getSize(a), max), min))
+			}
+		}
+
+		if err := cell.item.draw(pdf, cb, itemRect, clip); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (g *Grid) getContentSize(pdf *Document, contentBoxMax containingBlock) (size, error) {
+	l, err := g.layout(pdf, contentBoxMax, false)
+	if err != nil {
+		return size{}, err
+	}
+	return size{
+		w: tracksLength(l.columns, g.ColumnGap, 0, len(l.columns)),
+		h: tracksLength(l.rows, g.RowGap, 0, len(l.rows)),
+	}, nil
+}
+
+// getContentBaseline は最初の行の最も左にあるアイテムのベースラインの位置を返します
+func (g *Grid) getContentBaseline(pdf *Document, contentBox containingBlock) (float64, error) {
+	l, err := g.layout(pdf, contentBox, true)
+	if err != nil {
+		return 0, err
+	}
+
+	var first *gridCell
+	for i, cell := range l.cells {
+		if cell.row == 0 && (first == nil || cell.column < first.column) {
+			first = &l.cells[i]
+		}
+	}
+	if first == nil {
+		return contentBox.h, nil
+	}
+
+	area := g.area(l, *first)
+	return first.item.getBaseline(pdf, contentBox, size{w: area.w, h: area.h})
+}
+
+func (g *Grid) getFontSize(fontSize float64) float64 {
+	return fontSize
+}
+
+// getAbsoluteItems は絶対配置のアイテムを Order の順に返します
+func (g *Grid) getAbsoluteItems() []FlexItem {
+	_, items := partitionItems(g.Items)
+	return items
+}
+
+// clone はアイテムの配置と列の幅を固定した Grid の複製を返します。
+// cells の行は rows の先頭を0とした位置です
+func (g *Grid) clone(cells []gridCell, rows []TrackSize, columnWidths []float64, absolute []FlexItem) *Grid {
+	c := *g
+	c.self = &c
+	c.Rows = rows
+	c.Items = []FlexItem{}
+	for _, cell := range cells {
+		c.Items = append(c.Items, cell.item)
+	}
+	c.Items = append(c.Items, absolute...)
+	c.placement = cells
+	c.columnWidths = columnWidths
+	return &c
+}
+
+// splitContent はグリッドを行の境界でページに収まる部分とそれ以外に分割します。
+// 複数の行にまたがるアイテムの途中では分割せず、絶対配置のアイテムは前半部分に含めます
+func (g *Grid) splitContent(pdf *Document, contentBoxMax containingBlock, pageTop bool) (head, tail FlexItem, err error) {
+	defer wrap(&err, "grid.splitContent")
+
+	l, err := g.layout(pdf, contentBoxMax, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 複数の行にまたがるアイテムの途中では分割できない
+	breakable := make([]bool, len(l.rows)+1)
+	for i := range breakable {
+		breakable[i] = i > 0
+	}
+	for _, cell := range l.cells {
+		for i := cell.row + 1; i < cell.row+cell.rowSpan; i++ {
+			breakable[i] = false
+		}
+	}
+
+	// 収まる最後の境界を探す
+	split := -1
+	for i := 1; i < len(l.rows); i++ {
+		if tracksLength(l.rows, g.RowGap, 0, i) > contentBoxMax.h {
+			break
+		}
+		if breakable[i] {
+			split = i
+		}
+	}
+
+	if split < 0 {
+		if !pageTop {
+			return nil, g, nil
+		}
+		// ページの先頭では、収まらなくても最初の分割可能な境界までを含める
+		for i := 1; i < len(l.rows); i++ {
+			if breakable[i] {
+				split = i
+				break
+			}
+		}
+		if split < 0 {
+			return g, nil, nil
+		}
+	}
+
+	headCells, tailCells := []gridCell{}, []gridCell{}
+	for _, cell := range l.cells {
+		if cell.row < split {
+			headCells = append(headCells, cell)
+		} else {
+			cell.row -= split
+			tailCells = append(tailCells, cell)
+		}
+	}
+	n := minInt(split, len(g.Rows))
+	_, absolute := partitionItems(g.Items)
+	return g.clone(headCells, g.Rows[:n], l.columns, absolute), g.clone(tailCells, g.Rows[n:], l.columns, nil), nil
+}
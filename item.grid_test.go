@@ -0,0 +1,104 @@
+package flexpdf
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSizeTracks(t *testing.T) {
+	t.Run("fixed and fr", func(t *testing.T) {
+		defs := []TrackSize{TrackFixed(Pt(100)), TrackFr(1), TrackFr(3)}
+		got := sizeTracks(defs, nil, 500, 10, 500, 0, true)
+		want := []float64{100, 100, 300}
+		for i := range want {
+			if math.Abs(got[i]-want[i]) > 1e-6 {
+				t.Fatalf("got %v, want %v", got, want)
+			}
+		}
+	})
+
+	// 分配量が丸め誤差で 0 になっても終了すること
+	t.Run("denormal free space", func(t *testing.T) {
+		b := 22.439
+		defs := make([]TrackSize, 7)
+		for i := range defs {
+			defs[i] = TrackMinMax(Pt(b), Pt(b+1000))
+		}
+		available := 188.138
+		got := sizeTracks(defs, nil, available, 10, available, 0, true)
+		var sum float64
+		for _, w := range got {
+			sum += w
+		}
+		if math.Abs(sum-available) > 1e-6 {
+			t.Fatalf("sum %v, want %v", sum, available)
+		}
+	})
+}
+
+func TestPlaceItems(t *testing.T) {
+	// 行のみ指定されたアイテムは、空いている列がなければ列を追加して配置する
+	g := NewGrid(
+		[]TrackSize{TrackAuto, TrackAuto},
+		NewColumnBox().SetGridArea(1, 1, 1, 1),
+		NewColumnBox().SetGridArea(1, 2, 1, 1),
+		NewColumnBox().SetGridArea(1, 0, 1, 1),
+	)
+	cells, columns, rows := g.placeItems()
+	if columns != 3 || rows != 1 {
+		t.Fatalf("got %d columns and %d rows, want 3 and 1", columns, rows)
+	}
+	if c := cells[2]; c.row != 0 || c.column != 2 {
+		t.Errorf("got row %d, column %d, want 0, 2", c.row, c.column)
+	}
+}
+
+func TestGridSplitContent(t *testing.T) {
+	pdf := newTestPdf(t)
+	item := func() FlexItem { return NewColumnBox().SetHeight(20) }
+	newGrid := func() *Grid {
+		g := NewGrid([]TrackSize{TrackFixed(Pt(50)), TrackFixed(Pt(50))}).SetRowGap(5)
+		for i := 0; i < 8; i++ {
+			g.Items = append(g.Items, item())
+		}
+		return g
+	}
+	// 2行目の1列目が3行目にまたがる
+	spanned := newGrid()
+	spanned.Items[2] = NewColumnBox().SetHeight(20).SetGridSpan(2, 1)
+
+	tests := []struct {
+		name    string
+		grid    *Grid
+		height  float64
+		pageTop bool
+		head    int // head の行数（-1 は nil）
+		tail    int // tail の行数（-1 は nil）
+	}{
+		{"split", newGrid(), 70, false, 3, 1},
+		{"row span", spanned, 45, false, 1, 4},
+		{"too small", newGrid(), 10, false, -1, 4},
+		{"too small at page top", newGrid(), 10, true, 1, 3},
+	}
+	for _, tt := range tests {
+		head, tail, err := tt.grid.splitContent(pdf, containingBlock{size: size{w: 100, h: tt.height}, fontSize: defaultFontSize}, tt.pageTop)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := gridRowCount(head); got != tt.head {
+			t.Errorf("%s: head has %d rows, want %d", tt.name, got, tt.head)
+		}
+		if got := gridRowCount(tail); got != tt.tail {
+			t.Errorf("%s: tail has %d rows, want %d", tt.name, got, tt.tail)
+		}
+	}
+}
+
+// gridRowCount はグリッドの行数を返します。nil の場合は -1 を返します
+func gridRowCount(item FlexItem) int {
+	if item == nil {
+		return -1
+	}
+	_, _, rows := item.(*Grid).placeItems()
+	return rows
+}
@@ -0,0 +1,331 @@
+package flexpdf
+
+import (
+	"math"
+)
+
+// Table はセルを行と列に並べる表のエレメントです。
+// 列の幅は全ての行のセルの内容から求め、罫線は隣り合うセル同士で共有されます（border-collapse: collapse）。
+// セルの結合は各セルの GridArea の RowSpan/ColumnSpan で指定します。
+// ページをまたぐ場合は行の境界で分割し、先頭の HeaderRows 行を次のページでも繰り返します。
+// 絶対配置のセルは行から外し、表のパディングボックスを基準に配置します
+type Table struct {
+	// 共通フィールド
+	flexItemCommon[*Table]
+
+	Columns    []TrackSize // 列の幅。不足する列は TrackAuto として扱います
+	Rows       [][]FlexItem
+	HeaderRows int
+	CellBorder Border // 罫線。横線には Top を、縦線には Left を、外周には各辺の値を使用します
+
+	columnWidths []float64 // 分割された表で、元の表と列の幅を揃えるために使用します
+}
+
+func NewTable(columns ...TrackSize) *Table {
+	t := &Table{
+		Columns:    columns,
+		CellBorder: UniformedBorder(nil, BorderStyleSolid, 0),
+	}
+	t.flexItemCommon.init(t)
+	return t
+}
+
+// AddRow は行を追加します
+func (t *Table) AddRow(cells ...FlexItem) *Table {
+	t.Rows = append(t.Rows, cells)
+	return t
+}
+func (t *Table) SetHeaderRows(n int) *Table {
+	t.HeaderRows = n
+	return t
+}
+func (t *Table) SetCellBorder(border Border) *Table {
+	t.CellBorder = border
+	return t
+}
+
+// tableCell は表に配置されたセルです。行・列は0始まりです
+type tableCell struct {
+	item                          FlexItem
+	row, column, rowSpan, colSpan int
+}
+
+// tableLayout は列の幅と行の高さを確定した表です
+type tableLayout struct {
+	cells   []tableCell
+	columns []float64
+	rows    []float64
+	rule    Spacing // 罫線の幅。Top/Left は内側の罫線の幅を兼ねます
+}
+
+// placeCells はセルを配置し、列数を返します。
+// 各行のセルは Order の順に並べ、上の行から結合されたセルが占める位置は飛ばして配置します
+func (t *Table) placeCells() (cells []tableCell, columns int) {
+	columns = len(t.Columns)
+	occupied := map[[2]int]bool{}
+	for r, row := range t.Rows {
+		items, _ := partitionItems(row)
+		c := 0
+		for _, item := range items {
+			for occupied[[2]int{r, c}] {
+				c++
+			}
+			area := item.getGridArea()
+			rowSpan := minInt(maxInt(area.RowSpan, 1), len(t.Rows)-r)
+			colSpan := maxInt(area.ColumnSpan, 1)
+			for i := r; i < r+rowSpan; i++ {
+				for j := c; j < c+colSpan; j++ {
+					occupied[[2]int{i, j}] = true
+				}
+			}
+			cells = append(cells, tableCell{item: item, row: r, column: c, rowSpan: rowSpan, colSpan: colSpan})
+			c += colSpan
+			columns = maxInt(columns, c)
+		}
+	}
+	return cells, columns
+}
+
+// layout はセルを配置し、列の幅と行の高さを求めます
+func (t *Table) layout(pdf *Document, contentBox containingBlock) (*tableLayout, error) {
+	cells, columnCount := t.placeCells()
+	em := contentBox.fontSize
+	rule := t.CellBorder.Width.resolve(contentBox.w, em)
+
+	// 列の幅
+	columns := t.columnWidths
+	if columns == nil {
+		contribs := []trackContribution{}
+		for _, cell := range cells {
+			ms, err := cell.item.getMinSize(pdf, contentBox, contentBox.set(horizontal, 0))
+			if err != nil {
+				return nil, err
+			}
+			ps, err := cell.item.getPreferredSize(pdf, contentBox, contentBox.size)
+			if err != nil {
+				return nil, err
+			}
+			if !cell.item.isAutoSize(horizontal) {
+				ms.w = ps.w
+			}
+			contribs = append(contribs, trackContribution{start: cell.column, span: cell.colSpan, min: ms.w, max: ps.w})
+		}
+		available := contentBox.w - rule.Left - rule.Right
+		columns = sizeTracks(trackDefs(t.Columns, columnCount, TrackAuto), contribs, contentBox.w, em, available, rule.Left, true)
+	}
+
+	// 行の高さ（確定した列の幅で内容の高さを求める）
+	contribs := []trackContribution{}
+	for _, cell := range cells {
+		w := tracksLength(columns, rule.Left, cell.column, cell.colSpan)
+		ps, err := cell.item.getPreferredSize(pdf, contentBox, size{w: w, h: contentBox.h})
+		if err != nil {
+			return nil, err
+		}
+		contribs = append(contribs, trackContribution{start: cell.row, span: cell.rowSpan, min: ps.h, max: ps.h})
+	}
+	rows := sizeTracks(trackDefs(nil, len(t.Rows), TrackAuto), contribs, contentBox.h, em, contentBox.h, rule.Top, false)
+
+	return &tableLayout{cells: cells, columns: columns, rows: rows, rule: rule}, nil
+}
+
+// size は罫線を含む表全体のサイズを返します
+func (l *tableLayout) size() size {
+	return size{
+		w: l.rule.Left + tracksLength(l.columns, l.rule.Left, 0, len(l.columns)) + l.rule.Right,
+		h: l.rule.Top + tracksLength(l.rows, l.rule.Top, 0, len(l.rows)) + l.rule.Bottom,
+	}
+}
+
+// area はセルの領域を、表の左上を原点として返します
+func (l *tableLayout) area(cell tableCell) rect {
+	return rect{
+		x: l.rule.Left + trackOffset(l.columns, l.rule.Left, cell.column),
+		y: l.rule.Top + trackOffset(l.rows, l.rule.Top, cell.row),
+		w: tracksLength(l.columns, l.rule.Left, cell.column, cell.colSpan),
+		h: tracksLength(l.rows, l.rule.Top, cell.row, cell.rowSpan),
+	}
+}
+
+func (t *Table) drawContent(pdf *Document, r rect, cb containingBlock, clip *rect) (err error) {
+	defer wrap(&err, "table.drawContent")
+
+	l, err := t.layout(pdf, cb)
+	if err != nil {
+		return err
+	}
+
+	occupied := map[[2]int]bool{}
+	for _, cell := range l.cells {
+		for i := cell.row; i < cell.row+cell.rowSpan; i++ {
+			for j := cell.column; j < cell.column+cell.colSpan; j++ {
+				occupied[[2]int{i, j}] = true
+			}
+		}
+	}
+
+	for _, cell := range l.cells {
+		area := l.area(cell)
+		area.x += r.x
+		area.y += r.y
+
+		// サイズが未指定のセルは領域いっぱいに広げ、それ以外は領域の左上に配置する
+		ps, err := cell.item.getPreferredSize(pdf, cb, size{w: area.w, h: area.h})
+		if err != nil {
+			return err
+		}
+		itemRect := rect{x: area.x, y: area.y, w: ps.w, h: ps.h}
+		for _, a := range []axis{horizontal, vertical} {
+			if cell.item.isAutoSize(a) {
+				min, max := cell.item.getSizeLimits(cb, a)
+				itemRect = itemRect.setSize(a, math.Max(math.Min(area.getSize(a), max), min))
+			}
+		}
+		if err := cell.item.draw(pdf, cb, itemRect, clip); err != nil {
+			return err
+		}
+
+		// 罫線は各セルの上辺と左辺を描画し、右端・下端のセル（または隣にセルがない場合）のみ右辺・下辺を描画する
+		b := &t.CellBorder
+		x1, y1 := area.x-l.rule.Left/2, area.y-l.rule.Top/2
+		x2, y2 := area.x+area.w+l.rule.Left/2, area.y+area.h+l.rule.Top/2
+		last := cell.column+cell.colSpan == len(l.columns)
+		bottom := cell.row+cell.rowSpan == len(l.rows)
+		if last {
+			x2 = area.x + area.w + l.rule.Right/2
+		}
+		if bottom {
+			y2 = area.y + area.h + l.rule.Bottom/2
+		}
+
+		if err := b.drawPart(pdf, clip, x1, y1, x2, y1, b.Color.Top, l.rule.Top, b.Style.Top); err != nil {
+			return err
+		}
+		if err := b.drawPart(pdf, clip, x1, y1, x1, y2, b.Color.Left, l.rule.Left, b.Style.Left); err != nil {
+			return err
+		}
+		if last || !occupied[[2]int{cell.row, cell.column + cell.colSpan}] {
+			col, width, style := b.Color.Left, l.rule.Left, b.Style.Left
+			if last {
+				col, width, style = b.Color.Right, l.rule.Right, b.Style.Right
+			}
+			if err := b.drawPart(pdf, clip, x2, y1, x2, y2, col, width, style); err != nil {
+				return err
+			}
+		}
+		if bottom || !occupied[[2]int{cell.row + cell.rowSpan, cell.column}] {
+			col, width, style := b.Color.Top, l.rule.Top, b.Style.Top
+			if bottom {
+				col, width, style = b.Color.Bottom, l.rule.Bottom, b.Style.Bottom
+			}
+			if err := b.drawPart(pdf, clip, x1, y2, x2, y2, col, width, style); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+func (t *Table) getContentSize(pdf *Document, contentBoxMax containingBlock) (size, error) {
+	l, err := t.layout(pdf, contentBoxMax)
+	if err != nil {
+		return size{}, err
+	}
+	return l.size(), nil
+}
+
+// getContentBaseline は最初の行の最も左にあるセルのベースラインの位置を返します
+func (t *Table) getContentBaseline(pdf *Document, contentBox containingBlock) (float64, error) {
+	l, err := t.layout(pdf, contentBox)
+	if err != nil {
+		return 0, err
+	}
+	if len(l.cells) == 0 {
+		return contentBox.h, nil
+	}
+
+	area := l.area(l.cells[0])
+	b, err := l.cells[0].item.getBaseline(pdf, contentBox, size{w: area.w, h: area.h})
+	if err != nil {
+		return 0, err
+	}
+	return area.y + b, nil
+}
+
+func (t *Table) getFontSize(fontSize float64) float64 {
+	return fontSize
+}
+
+// getAbsoluteItems は絶対配置のセルを、行ごとに Order の順で返します
+func (t *Table) getAbsoluteItems() []FlexItem {
+	items := []FlexItem{}
+	for _, row := range t.Rows {
+		_, absolute := partitionItems(row)
+		items = append(items, absolute...)
+	}
+	return items
+}
+
+// clone は Rows を差し替え、列の幅を固定した Table の複製を返します
+func (t *Table) clone(rows [][]FlexItem, columnWidths []float64) *Table {
+	c := *t
+	c.self = &c
+	c.Rows = rows
+	c.columnWidths = columnWidths
+	return &c
+}
+
+// splitContent は表を行の境界でページに収まる部分とそれ以外に分割します。
+// 結合されたセルの途中では分割せず、後半部分の先頭にはヘッダー行を繰り返します
+func (t *Table) splitContent(pdf *Document, contentBoxMax containingBlock, pageTop bool) (head, tail FlexItem, err error) {
+	defer wrap(&err, "table.splitContent")
+
+	l, err := t.layout(pdf, contentBoxMax)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	header := minInt(t.HeaderRows, len(t.Rows))
+
+	// 結合されたセルをまたぐ境界では分割できない
+	breakable := make([]bool, len(t.Rows)+1)
+	for i := range breakable {
+		breakable[i] = i > header
+	}
+	for _, cell := range l.cells {
+		for i := cell.row + 1; i < cell.row+cell.rowSpan; i++ {
+			breakable[i] = false
+		}
+	}
+
+	// 収まる最後の境界を探す
+	split := -1
+	for i := header + 1; i < len(t.Rows); i++ {
+		h := l.rule.Top + tracksLength(l.rows, l.rule.Top, 0, i) + l.rule.Bottom
+		if h > contentBoxMax.h {
+			break
+		}
+		if breakable[i] {
+			split = i
+		}
+	}
+
+	if split < 0 {
+		if !pageTop {
+			return nil, t, nil
+		}
+		// ページの先頭では、収まらなくても最初の分割可能な境界までを含める
+		for i := header + 1; i < len(t.Rows); i++ {
+			if breakable[i] {
+				split = i
+				break
+			}
+		}
+		if split < 0 {
+			return t, nil, nil
+		}
+	}
+
+	headRows := t.Rows[:split]
+	tailRows := append(append([][]FlexItem{}, t.Rows[:header]...), t.Rows[split:]...)
+	return t.clone(headRows, l.columns), t.clone(tailRows, l.columns), nil
+}
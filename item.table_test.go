@@ -0,0 +1,67 @@
+package flexpdf
+
+import "testing"
+
+func TestTableSplitContent(t *testing.T) {
+	pdf := newTestPdf(t)
+	cell := func() FlexItem { return NewColumnBox().SetHeight(20) }
+	newTable := func() *Table {
+		table := NewTable(TrackFixed(Pt(50)), TrackFixed(Pt(50))).SetHeaderRows(1)
+		for i := 0; i < 6; i++ {
+			table.AddRow(cell(), cell())
+		}
+		return table
+	}
+	// 3行目の1列目が4行目にまたがる
+	spanned := newTable()
+	spanned.Rows[2][0] = NewColumnBox().SetHeight(20).SetGridSpan(2, 1)
+	spanned.Rows[3] = spanned.Rows[3][1:]
+
+	tests := []struct {
+		name    string
+		table   *Table
+		height  float64
+		pageTop bool
+		head    int // head の行数（-1 は nil）
+		tail    int // tail の行数（-1 は nil）
+	}{
+		{"split with header", newTable(), 65, false, 3, 4},
+		{"row span", spanned, 65, false, 2, 5},
+		{"too small", newTable(), 10, false, -1, 6},
+		{"too small at page top", newTable(), 10, true, 2, 5},
+	}
+	for _, tt := range tests {
+		head, tail, err := tt.table.splitContent(pdf, containingBlock{size: size{w: 100, h: tt.height}, fontSize: defaultFontSize}, tt.pageTop)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := rowCount(head); got != tt.head {
+			t.Errorf("%s: head has %d rows, want %d", tt.name, got, tt.head)
+		}
+		if got := rowCount(tail); got != tt.tail {
+			t.Errorf("%s: tail has %d rows, want %d", tt.name, got, tt.tail)
+		}
+	}
+}
+
+// rowCount は表の行数を返します。nil の場合は -1 を返します
+func rowCount(item FlexItem) int {
+	if item == nil {
+		return -1
+	}
+	return len(item.(*Table).Rows)
+}
+
+func TestTableAbsoluteCell(t *testing.T) {
+	absolute := NewColumnBox().SetPosition(PositionAbsolute)
+	table := NewTable().AddRow(absolute, NewColumnBox()).AddRow(NewColumnBox())
+
+	// 絶対配置のセルは列を占めない
+	cells, columns := table.placeCells()
+	if len(cells) != 2 || columns != 1 {
+		t.Fatalf("got %d cells and %d columns, want 2 and 1", len(cells), columns)
+	}
+	if items := table.getAbsoluteItems(); len(items) != 1 || items[0] != absolute {
+		t.Errorf("got %v, want the absolute cell", items)
+	}
+}
@@ -14,30 +14,56 @@ const (
 	TextAlignBegin TextAlign = iota
 	TextAlignCenter
 	TextAlignEnd
+	TextAlignJustify // 行の幅に合わせて、欧文の単語間と CJK の文字間を均等に広げます
 )
 
+// TextOverflow は MaxLines を超えて省略されるテキストの扱いです
+// https://www.w3.org/TR/css-overflow-3/#text-overflow
+type TextOverflow string
+
+const (
+	TextOverflowClip     TextOverflow = "clip"     // 最後の行をそのまま表示します
+	TextOverflowEllipsis TextOverflow = "ellipsis" // 最後の行の末尾を「…」に置き換えます
+)
+
+// ellipsis は TextOverflowEllipsis で使用する省略記号です
+const ellipsis = "…"
+
 // Text はテキストを扱うエレメントです
 // TODO family, size, color, text は Spanのスライスにする
 type Text struct {
 	flexItemCommon[*Text]
 
-	Align TextAlign
-	Runs  []*TextRun
+	Align              TextAlign
+	AlignLast          TextAlign // Align が TextAlignJustify の場合の、段落の最終行（改行コードの直前の行と末尾の行）の揃え方
+	MaxLines           int       // 表示する最大の行数。0 の場合は制限しません
+	TextOverflow       TextOverflow
+	Kinsoku            Kinsoku
+	HangingPunctuation bool // 行末の句読点を行の幅からはみ出して配置できるかどうか（ぶら下げ）
+	Runs               []*TextRun
+
+	paragraphEnds []bool // 分割された Text で、改行コードで区切られた各行が段落の最終行かどうか
 }
 
 type TextRun struct {
-	Color      color.Color
-	FontSize   float64
-	FontFamily string
-	LineHeight float64
-	Text       string
+	Color            color.Color
+	FontSize         Length // % と em は Text のフォントサイズを基準とします
+	FontFamily       string
+	FallbackFamilies []string // FontFamily が字形を持たない文字に使用するフォントの候補
+	FontWeight       FontWeight
+	FontStyle        FontStyle
+	LineHeight       float64
+	Decoration       TextDecoration
+	Text             string
 }
 
 func NewRun(text string) *TextRun {
 	return &TextRun{
 		Color:      color.Black,
-		FontSize:   10,
+		FontSize:   Em(1),
 		FontFamily: "",
+		FontWeight: FontWeightNormal,
+		FontStyle:  FontStyleNormal,
 		LineHeight: 1,
 		Text:       text,
 	}
@@ -47,13 +73,45 @@ func (r *TextRun) SetColor(c color.Color) *TextRun {
 	return r
 }
 func (r *TextRun) SetFontSize(s float64) *TextRun {
+	r.FontSize = Pt(s)
+	return r
+}
+
+// SetFontSizeLength は Length でフォントサイズを設定します。% と em は Text のフォントサイズを基準とします
+func (r *TextRun) SetFontSizeLength(s Length) *TextRun {
 	r.FontSize = s
 	return r
 }
+
+// resolveFontSize は Text のフォントサイズ parent に対して FontSize を解決した、ポイント単位のフォントサイズを返します
+func (r *TextRun) resolveFontSize(parent float64) float64 {
+	return r.FontSize.resolve(parent, parent)
+}
 func (r *TextRun) SetFontFamily(f string) *TextRun {
 	r.FontFamily = f
 	return r
 }
+func (r *TextRun) SetFallbackFamilies(families ...string) *TextRun {
+	r.FallbackFamilies = families
+	return r
+}
+func (r *TextRun) SetFontWeight(w FontWeight) *TextRun {
+	r.FontWeight = w
+	return r
+}
+func (r *TextRun) SetFontStyle(s FontStyle) *TextRun {
+	r.FontStyle = s
+	return r
+}
+
+// font は FontFamily・FontWeight・FontStyle に最も近い登録されたフォントを返します
+func (r *TextRun) font(pdf *Document) fontSelection {
+	return pdf.fonts.selectFont(r.FontFamily, r.FontWeight, r.FontStyle)
+}
+func (r *TextRun) SetDecoration(d TextDecoration) *TextRun {
+	r.Decoration = d
+	return r
+}
 func (r *TextRun) SetLineHeight(lineHeight float64) *TextRun {
 	r.LineHeight = lineHeight
 	return r
@@ -63,10 +121,12 @@ func (r *TextRun) SetText(t string) *TextRun {
 	return r
 }
 
-// splitToNBR は改行コードのみを考慮して noBrRunのリストに分割します
-func (r *TextRun) splitWithNewline() []noBrRun {
+// splitToNBR は改行コードのみを考慮して noBrRunのリストに分割します。
+// FontSize は Text のフォントサイズ fontSize に対して解決し、ポイント単位にします
+func (r *TextRun) splitWithNewline(fontSize float64) []noBrRun {
 	nbrs := []noBrRun{}
-	nbr := noBrRun{*r}
+	nbr := noBrRun{TextRun: *r}
+	nbr.FontSize = Pt(r.resolveFontSize(fontSize))
 	for _, text := range strings.Split(r.Text, "\n") {
 		nbr.Text = text
 		nbrs = append(nbrs, nbr)
@@ -74,27 +134,332 @@ func (r *TextRun) splitWithNewline() []noBrRun {
 	return nbrs
 }
 
+// splitWithFont は各文字を、FontFamily と FallbackFamilies のうち最初にその字形を持つフォントで描画するよう、
+// FontFamily を差し替えた noBrRun のリストに分割します。
+// どのフォントも字形を持たない文字は FontFamily で描画し、空白は直前の文字と同じフォントで描画します。
+// 分割した位置は改行の機会にはなりません（改行の機会は paragraph で段落全体から求めます）
+func (r *noBrRun) splitWithFont(pdf *Document) []noBrRun {
+	if len(r.FallbackFamilies) == 0 {
+		return []noBrRun{*r}
+	}
+
+	families := append([]string{r.FontFamily}, r.FallbackFamilies...)
+	nbrs := []noBrRun{}
+	var text []rune
+	family := r.FontFamily
+	for _, ch := range r.Text {
+		f := family
+		if !isBreakSpace(ch) || !r.hasGlyph(pdf, family, ch) {
+			f = r.FontFamily
+			for _, candidate := range families {
+				if r.hasGlyph(pdf, candidate, ch) {
+					f = candidate
+					break
+				}
+			}
+		}
+		if f != family && len(text) != 0 {
+			nbr := *r
+			nbr.FontFamily = family
+			nbr.Text = string(text)
+			nbrs = append(nbrs, nbr)
+			text = nil
+		}
+		family = f
+		text = append(text, ch)
+	}
+
+	nbr := *r
+	nbr.FontFamily = family
+	nbr.Text = string(text)
+	return append(nbrs, nbr)
+}
+
+// hasGlyph は family のフォントが ch の字形を持つかどうかを返します。
+// flexpdf に登録されていないフォントは全ての字形を持つものとみなします
+func (r *noBrRun) hasGlyph(pdf *Document, family string, ch rune) bool {
+	face := pdf.fonts.selectFont(family, r.FontWeight, r.FontStyle).face
+	return face == nil || face.hasGlyph(ch)
+}
+
 // 改行を含まない TextRun
 type noBrRun struct {
 	TextRun
+	charSpacing float64 // 各文字の後に加える間隔。追い込みなどで行の幅を調整する場合に使用します
+	wordSpacing float64 // 両端揃えで、欧文の単語間と CJK の文字間に加える間隔
+	justifyEnd  bool    // 末尾の文字の後（次の noBrRun との間）にも wordSpacing を加えるかどうか
+}
+
+// fontSize はポイント単位のフォントサイズを返します。FontSize は splitWithNewline で解決済みです
+func (r *noBrRun) fontSize() float64 {
+	return r.FontSize.resolve(0, 0)
 }
 
-func (r *noBrRun) size(pdf *gopdf.GoPdf) (size, error) {
-	if err := pdf.SetFont(r.FontFamily, "", r.FontSize); err != nil {
+func (r *noBrRun) size(pdf *Document) (size, error) {
+	if err := pdf.SetFont(r.font(pdf).name, "", r.fontSize()); err != nil {
 		return size{}, err
 	}
 	w, err := pdf.MeasureTextWidth(r.Text)
 	if err != nil {
 		return size{}, err
 	}
-	return size{w: w, h: r.FontSize * r.LineHeight}, nil
+	w += r.charSpacing * float64(len([]rune(r.Text)))
+	for _, gap := range r.justifyGaps() {
+		if gap {
+			w += r.wordSpacing
+		}
+	}
+	return size{w: w, h: r.fontSize() * r.LineHeight}, nil
 }
-func (r *noBrRun) splitWithWidth(pdf *gopdf.GoPdf, widthLimit float64) (*noBrRun, *noBrRun, error) {
+
+// justifyGaps は各文字の後が両端揃えで間隔を広げる位置かどうかを返します
+func (r *noBrRun) justifyGaps() []bool {
+	runes := []rune(r.Text)
+	gaps := make([]bool, len(runes))
+	for i := 0; i < len(runes)-1; i++ {
+		gaps[i] = isJustifyGap(runes[i], runes[i+1])
+	}
+	if len(runes) != 0 {
+		gaps[len(runes)-1] = r.justifyEnd
+	}
+	return gaps
+}
+
+// paragraph は改行コードで区切られた1段落分の noBrRun です。
+// 改行の機会は noBrRun の境界をまたいで、段落全体の文字列から求めます
+type paragraph struct {
+	nbrs    []noBrRun
+	offsets []int  // 各 noBrRun の先頭の文字の、段落における位置。末尾に len(runes) を含みます
+	runes   []rune // 全ての noBrRun の文字を連結したもの
+	breaks  []bool // runes の各位置の直前で改行できるかどうか
+}
+
+func newParagraph(nbrs []noBrRun, kinsoku Kinsoku) *paragraph {
+	p := &paragraph{nbrs: nbrs}
+	for _, nbr := range nbrs {
+		p.offsets = append(p.offsets, len(p.runes))
+		p.runes = append(p.runes, []rune(nbr.Text)...)
+	}
+	p.offsets = append(p.offsets, len(p.runes))
+	p.breaks = breakOpportunities(p.runes, kinsoku)
+	return p
+}
+
+// splitLines は段落を widthLimit に収まる行に分割します。行頭の空白は取り除きます。
+// 収まる改行位置がない場合は行の幅より長い単語として文字単位で分割しますが、
+// widthLimit が 0（min-content の計測）の場合は単語を分割しません。
+// rule によっては、行末の句読点のぶら下げや行頭禁則文字の追い込みによって widthLimit を超えることがあります
+func (p *paragraph) splitLines(pdf *Document, widthLimit float64, rule lineBreakRule) ([][]noBrRun, error) {
+	lines := [][]noBrRun{}
+	begin := 0
+	for {
+		start := begin
+		for start < len(p.runes) && isBreakSpace(p.runes[start]) {
+			start++
+		}
+		end, err := p.lineEnd(pdf, start, widthLimit, rule)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, p.slice(begin, end))
+		if end >= len(p.runes) {
+			return lines, nil
+		}
+		begin = end
+	}
+}
+
+// lineEnd は start から始まる行が収まる最後の改行位置を返します
+func (p *paragraph) lineEnd(pdf *Document, start int, widthLimit float64, rule lineBreakRule) (int, error) {
+	if start == len(p.runes) || widthLimit < 0 {
+		return len(p.runes), nil
+	}
+
+	at := -1
+	var w float64 // start から直前の改行位置までの幅
+	prev := start
+	for i := start + 1; i <= len(p.runes); i++ {
+		if !p.breaks[i] {
+			continue
+		}
+		// 行末の空白は幅に含めない
+		trim := i
+		for trim > prev && isBreakSpace(p.runes[trim-1]) {
+			trim--
+		}
+		word, err := p.width(pdf, prev, trim)
+		if err != nil {
+			return 0, err
+		}
+		if w+word > widthLimit {
+			ok, err := p.fitsWithKinsoku(pdf, start, trim, w+word, widthLimit, rule)
+			if err != nil {
+				return 0, err
+			}
+			if ok {
+				at = i
+			}
+			break
+		}
+		space, err := p.width(pdf, trim, i)
+		if err != nil {
+			return 0, err
+		}
+		w += word + space
+		at = i
+		prev = i
+	}
+
+	switch {
+	case at >= 0:
+		return at, nil
+	case widthLimit == 0:
+		// 最初の単語を1行とする
+		at = start + 1
+		for !p.breaks[at] {
+			at++
+		}
+		return at, nil
+	default:
+		return p.charEnd(pdf, start, widthLimit)
+	}
+}
+
+// charEnd は単語の境界に関わらず、start からの文字が widthLimit に収まる最後の位置を返します。
+// 1文字も収まらない場合でも、少なくとも1文字を含めます
+func (p *paragraph) charEnd(pdf *Document, start int, widthLimit float64) (int, error) {
+	var w float64
+	for i := start + 1; i <= len(p.runes); i++ {
+		cw, err := p.width(pdf, i-1, i)
+		if err != nil {
+			return 0, err
+		}
+		w += cw
+		if w > widthLimit {
+			return maxInt(i-1, start+1), nil
+		}
+	}
+	return len(p.runes), nil
+}
+
+// fitsWithKinsoku は widthLimit を超える start から end までの行（幅 w）を、
+// ぶら下げまたは追い込みによって1行に収められるかどうかを返します
+func (p *paragraph) fitsWithKinsoku(pdf *Document, start, end int, w, widthLimit float64, rule lineBreakRule) (bool, error) {
+	if rule.kinsoku == KinsokuNone || end-start < 2 {
+		return false, nil
+	}
+	last := p.runes[end-1]
+
+	// 行末の句読点1文字だけがはみ出す場合はぶら下げる
+	if rule.hanging && isHangingPunctuation(last) {
+		mark, err := p.width(pdf, end-1, end)
+		if err != nil {
+			return false, err
+		}
+		if w-mark <= widthLimit {
+			return true, nil
+		}
+	}
+
+	// 行頭禁則文字を除いた部分が収まり、はみ出す幅を約物のアキで詰められる場合は追い込む
+	if rule.kinsoku == KinsokuPushIn && isNoBreakBefore(last) {
+		n := end
+		for n > start && isNoBreakBefore(p.runes[n-1]) {
+			n--
+		}
+		tail, err := p.width(pdf, n, end)
+		if err != nil {
+			return false, err
+		}
+		var compressible float64
+		for i := start; i < end; i++ {
+			if isFullWidthPunctuation(p.runes[i]) {
+				compressible += p.nbrs[p.owner(i)].fontSize() / 2
+			}
+		}
+		if w-tail <= widthLimit && w-widthLimit <= compressible {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// width は start から end までの文字を、それぞれの noBrRun のフォントで描画した場合の幅を返します
+func (p *paragraph) width(pdf *Document, start, end int) (float64, error) {
+	var w float64
+	for i := range p.nbrs {
+		s, e := maxInt(start, p.offsets[i]), minInt(end, p.offsets[i+1])
+		if s >= e {
+			continue
+		}
+		if err := pdf.SetFont(p.nbrs[i].font(pdf).name, "", p.nbrs[i].fontSize()); err != nil {
+			return 0, err
+		}
+		nw, err := pdf.MeasureTextWidth(string(p.runes[s:e]))
+		if err != nil {
+			return 0, err
+		}
+		w += nw
+	}
+	return w, nil
+}
+
+// owner は位置 i の文字を含む noBrRun のインデックスを返します
+func (p *paragraph) owner(i int) int {
+	for n := range p.nbrs {
+		if i < p.offsets[n+1] {
+			return n
+		}
+	}
+	return len(p.nbrs) - 1
+}
+
+// slice は begin から end までの文字を含む noBrRun のリストを、行頭の空白を取り除いて返します。
+// 空の noBrRun も行の高さに寄与するため、その位置を含む行に含めます
+func (p *paragraph) slice(begin, end int) []noBrRun {
+	nbrs := []noBrRun{}
+	for i, nbr := range p.nbrs {
+		ns, ne := p.offsets[i], p.offsets[i+1]
+		if ns == ne {
+			if begin <= ns && (ns < end || end == len(p.runes)) {
+				nbrs = append(nbrs, nbr)
+			}
+			continue
+		}
+		if ns >= end || ne <= begin {
+			continue
+		}
+		nbr.Text = string(p.runes[maxInt(begin, ns):minInt(end, ne)])
+		nbrs = append(nbrs, nbr)
+	}
+
+	trimming := true
+	for i := 0; i < len(nbrs) && trimming; i++ {
+		nbrs[i].Text = strings.TrimLeft(nbrs[i].Text, " \t")
+		trimming = nbrs[i].Text == ""
+	}
+	return nbrs
+}
+
+// compressible は runes に含まれる全角の約物のアキ（字幅の半分）の合計を返します
+func (r *noBrRun) compressible(runes []rune) float64 {
+	var c float64
+	for _, ch := range runes {
+		if isFullWidthPunctuation(ch) {
+			c += r.fontSize() / 2
+		}
+	}
+	return c
+}
+
+// splitWithCharWidth は単語の境界に関わらず、widthLimit に収まる最後の文字で分割します。
+// 1文字も収まらない場合でも、head には少なくとも1文字を含めます
+func (r *noBrRun) splitWithCharWidth(pdf *Document, widthLimit float64) (*noBrRun, *noBrRun, error) {
 	if widthLimit < 0 {
 		return r, nil, nil
 	}
 
-	if err := pdf.SetFont(r.FontFamily, "", r.FontSize); err != nil {
+	if err := pdf.SetFont(r.font(pdf).name, "", r.fontSize()); err != nil {
 		return nil, nil, err
 	}
 
@@ -108,9 +473,13 @@ func (r *noBrRun) splitWithWidth(pdf *gopdf.GoPdf, widthLimit float64) (*noBrRun
 			if i > 1 {
 				i--
 			}
-			nbr1 := noBrRun{TextRun: r.TextRun}
+			if i == len(runes) {
+				return r, nil, nil
+			}
+			nbr1 := *r
 			nbr1.Text = string(runes[:i])
-			nbr2 := noBrRun{TextRun: r.TextRun}
+			nbr1.justifyEnd = isJustifyGap(runes[i-1], runes[i])
+			nbr2 := *r
 			nbr2.Text = string(runes[i:])
 			return &nbr1, &nbr2, nil
 		}
@@ -118,8 +487,8 @@ func (r *noBrRun) splitWithWidth(pdf *gopdf.GoPdf, widthLimit float64) (*noBrRun
 	return r, nil, nil
 }
 
-func (r *noBrRun) draw(pdf *gopdf.GoPdf) error {
-	if err := pdf.SetFont(r.FontFamily, "", r.FontSize); err != nil {
+func (r *noBrRun) draw(pdf *Document, clip *rect) error {
+	if err := pdf.SetFont(r.font(pdf).name, "", r.fontSize()); err != nil {
 		return err
 	}
 	if err := setColor(pdf, r.Color); err != nil {
@@ -131,12 +500,319 @@ func (r *noBrRun) draw(pdf *gopdf.GoPdf) error {
 		return err
 	}
 
-	return pdf.Cell(&gopdf.Rect{W: s.w, H: s.h}, r.Text)
+	x, y := pdf.GetX(), pdf.GetY()
+	if err := r.drawText(pdf, s); err != nil {
+		return err
+	}
+	if err := r.drawDecoration(pdf, x, y, s.w, clip); err != nil {
+		return err
+	}
+	pdf.SetXY(x+s.w, y)
+	return nil
 }
 
+// drawText は現在の位置から、サイズ s の noBrRun の文字を描画します
+func (r *noBrRun) drawText(pdf *Document, s size) error {
+	if r.wordSpacing == 0 {
+		return r.cell(pdf, s.w, s.h, r.Text)
+	}
+
+	// 間隔を広げる位置で区切って描画する
+	runes := []rune(r.Text)
+	start := 0
+	for i, gap := range r.justifyGaps() {
+		if !gap && i != len(runes)-1 {
+			continue
+		}
+		seg := noBrRun{TextRun: r.TextRun, charSpacing: r.charSpacing}
+		seg.Text = string(runes[start : i+1])
+		ss, err := seg.size(pdf)
+		if err != nil {
+			return err
+		}
+		x := pdf.GetX()
+		if err := r.cell(pdf, ss.w, s.h, seg.Text); err != nil {
+			return err
+		}
+		if gap {
+			pdf.SetX(x + ss.w + r.wordSpacing)
+		}
+		start = i + 1
+	}
+	return nil
+}
+
+// cell は現在の位置から幅 w の text を描画し、現在の位置を w だけ進めます。
+// 選ばれたフォントが要求された太さや斜体を持たない場合は、それらを合成します
+func (r *noBrRun) cell(pdf *Document, w, h float64, text string) error {
+	font := r.font(pdf)
+	x, y := pdf.GetX(), pdf.GetY()
+	defer pdf.SetXY(x+w, y)
+
+	if !font.syntheticOblique {
+		return r.strike(pdf, x, y, w, h, text, font.syntheticBold)
+	}
+
+	// 斜体の合成：gopdf は任意の変換行列を設定できず斜行（skew）を扱えないため、
+	// 1文字ずつベースライン上の位置を中心に回転させて近似する。字形は傾くが、縦線の高さは cos の分だけ縮む
+	baseline := y + r.ascent(pdf)
+	for _, ch := range text {
+		cw, err := pdf.MeasureTextWidth(string(ch))
+		if err != nil {
+			return err
+		}
+		pdf.Rotate(-syntheticObliqueAngle, x, baseline)
+		err = r.strike(pdf, x, y, cw, h, string(ch), font.syntheticBold)
+		pdf.RotateReset()
+		if err != nil {
+			return err
+		}
+		x += cw + r.charSpacing
+	}
+	return nil
+}
+
+// strike は (x, y) から text を描画します。bold の場合は少しずらして重ねて描画し、太字を合成します
+func (r *noBrRun) strike(pdf *Document, x, y, w, h float64, text string, bold bool) error {
+	if r.charSpacing != 0 {
+		if err := pdf.SetCharSpacing(r.charSpacing); err != nil {
+			return err
+		}
+		defer pdf.SetCharSpacing(0)
+	}
+	if bold {
+		pdf.SetXY(x+r.fontSize()*syntheticBoldOffset, y)
+		if err := pdf.Cell(&gopdf.Rect{W: w, H: h}, text); err != nil {
+			return err
+		}
+	}
+	pdf.SetXY(x, y)
+	return pdf.Cell(&gopdf.Rect{W: w, H: h}, text)
+}
+
+// clip は x から描画する場合にクリップ領域 clip の左右に収まる部分と、その描画位置を返します。
+// 合成した太字や斜体のはみ出しを含めて収まる文字のみを残し、1文字も収まらない場合は nil を返します
+func (r *noBrRun) clip(pdf *Document, x float64, clip rect) (*noBrRun, float64, error) {
+	if err := pdf.SetFont(r.font(pdf).name, "", r.fontSize()); err != nil {
+		return nil, 0, err
+	}
+
+	// 各文字の字幅と、その後の間隔
+	runes := []rune(r.Text)
+	glyphs := make([]float64, len(runes))
+	spacings := make([]float64, len(runes))
+	for i, gap := range r.justifyGaps() {
+		w, err := pdf.MeasureTextWidth(string(runes[i]))
+		if err != nil {
+			return nil, 0, err
+		}
+		glyphs[i], spacings[i] = w, r.charSpacing
+		if gap {
+			spacings[i] += r.wordSpacing
+		}
+	}
+
+	left, right := r.overhang(pdf)
+
+	// 左端をはみ出す文字を取り除く
+	start := 0
+	for ; start < len(runes) && x-left < clip.x-clipEpsilon; start++ {
+		x += glyphs[start] + spacings[start]
+	}
+
+	// 右端をはみ出す文字を取り除く
+	right = clip.x + clip.w - right
+	end := start
+	for w := x; end < len(runes) && w+glyphs[end] <= right+clipEpsilon; end++ {
+		w += glyphs[end] + spacings[end]
+	}
+	if start == end {
+		return nil, 0, nil
+	}
+
+	visible := *r
+	visible.Text = string(runes[start:end])
+	visible.justifyEnd = r.justifyGaps()[end-1]
+	return &visible, x, nil
+}
+
+// overhang は合成した太字や斜体の字形が、字幅を超えて左右にはみ出す幅を返します。
+// 斜体はベースラインを中心に傾けるため、アセンダー側は右に、ディセンダー側は左にはみ出します
+func (r *noBrRun) overhang(pdf *Document) (left, right float64) {
+	font := r.font(pdf)
+	if font.syntheticBold {
+		right += r.fontSize() * syntheticBoldOffset
+	}
+	if font.syntheticOblique {
+		tan := math.Tan(syntheticObliqueAngle * math.Pi / 180)
+		left += r.descent(pdf) * tan
+		right += r.ascent(pdf) * tan
+	}
+	return left, right
+}
+
+// ascent は描画領域の上端からベースラインまでの距離を返します
+func (r *noBrRun) ascent(pdf *Document) float64 {
+	if face := r.font(pdf).face; face != nil {
+		return face.ascender(r.fontSize())
+	}
+	return r.fontSize() * defaultAscender
+}
+
+// descent はベースラインから字形の下端までの距離を返します
+func (r *noBrRun) descent(pdf *Document) float64 {
+	if face := r.font(pdf).face; face != nil {
+		return face.descender(r.fontSize())
+	}
+	return r.fontSize() * defaultDescender
+}
+
+// textLine は1行分の noBrRun です。
+// 各 noBrRun はベースラインを揃えて配置されます
 type textLine struct {
-	size size
-	nbrs []noBrRun
+	endOfParagraph bool // 段落の最終行かどうか
+	size           size
+	ascent         float64 // 行の上端からベースラインまでの距離
+	descent        float64 // ベースラインから行の下端までの距離
+	nbrs           []noBrRun
+}
+
+func (l *textLine) add(pdf *Document, nbr noBrRun, s size) {
+	a := nbr.ascent(pdf)
+	l.nbrs = append(l.nbrs, nbr)
+	l.ascent = math.Max(l.ascent, a)
+	l.descent = math.Max(l.descent, s.h-a)
+	l.size.w += s.w
+	l.size.h = l.ascent + l.descent
+}
+
+// finish は行末の空白を取り除き、行のサイズを求め直します。
+// 行が widthLimit を超える場合、句読点のぶら下げ、または行頭禁則文字の追い込みによる文字間の調整を行います
+func (l *textLine) finish(pdf *Document, widthLimit float64, rule lineBreakRule) error {
+	nbrs := l.nbrs
+	*l = textLine{endOfParagraph: l.endOfParagraph}
+
+	trimming := true
+	for i := len(nbrs) - 1; i >= 0 && trimming; i-- {
+		nbrs[i].Text = strings.TrimRight(nbrs[i].Text, " \t")
+		trimming = nbrs[i].Text == ""
+	}
+	if err := l.addAll(pdf, nbrs); err != nil {
+		return err
+	}
+
+	overflow := l.size.w - widthLimit
+	if widthLimit < 0 || overflow <= 0 || rule.kinsoku == KinsokuNone {
+		return nil
+	}
+
+	var last *noBrRun
+	var runes []rune
+	var compressible float64
+	for i := range nbrs {
+		rs := []rune(nbrs[i].Text)
+		if len(rs) != 0 {
+			last = &nbrs[i]
+		}
+		runes = append(runes, rs...)
+		compressible += nbrs[i].compressible(rs)
+	}
+	if last == nil || len(runes) < 2 {
+		return nil
+	}
+	lastRune := runes[len(runes)-1]
+
+	// ぶら下げた句読点は行の幅に含めない
+	if rule.hanging && isHangingPunctuation(lastRune) {
+		mark := *last
+		mark.Text = string(lastRune)
+		ms, err := mark.size(pdf)
+		if err != nil {
+			return err
+		}
+		if overflow <= ms.w {
+			l.size.w -= ms.w
+			return nil
+		}
+	}
+
+	// 追い込んだ行は文字間を詰めて widthLimit に収める
+	if rule.kinsoku == KinsokuPushIn && isNoBreakBefore(lastRune) && overflow <= compressible {
+		spacing := -overflow / float64(len(runes)-1)
+		for i := range nbrs {
+			nbrs[i].charSpacing = spacing
+		}
+		*l = textLine{endOfParagraph: l.endOfParagraph}
+		if err := l.addAll(pdf, nbrs); err != nil {
+			return err
+		}
+		l.size.w = widthLimit // 最後の文字の後の間隔は含めない
+	}
+	return nil
+}
+
+// justify は欧文の単語間と CJK の文字間を均等に広げ、行の幅を width に揃えます
+func (l *textLine) justify(pdf *Document, width float64) error {
+	extra := width - l.size.w
+	if extra <= 0 {
+		return nil
+	}
+
+	nbrs := l.nbrs
+	gaps := 0
+	for i := range nbrs {
+		runes := []rune(nbrs[i].Text)
+		nbrs[i].justifyEnd = false
+		if len(runes) == 0 {
+			continue
+		}
+		// 次の noBrRun の先頭の文字との間
+		for _, next := range nbrs[i+1:] {
+			if nextRunes := []rune(next.Text); len(nextRunes) != 0 {
+				nbrs[i].justifyEnd = isJustifyGap(runes[len(runes)-1], nextRunes[0])
+				break
+			}
+		}
+		for _, gap := range nbrs[i].justifyGaps() {
+			if gap {
+				gaps++
+			}
+		}
+	}
+	if gaps == 0 {
+		return nil
+	}
+
+	for i := range nbrs {
+		nbrs[i].wordSpacing = extra / float64(gaps)
+	}
+	*l = textLine{endOfParagraph: l.endOfParagraph}
+	return l.addAll(pdf, nbrs)
+}
+
+// isJustifyGap は文字 a と b の間が、両端揃えで間隔を広げる位置かどうかを返します。
+// 欧文では単語間の空白の後、CJK の文字ではその前後です
+func isJustifyGap(a, b rune) bool {
+	switch {
+	case isBreakSpace(a):
+		return !isBreakSpace(b)
+	case isBreakSpace(b):
+		return false
+	default:
+		return isCJK(a) || isCJK(b)
+	}
+}
+
+// addAll は全ての noBrRun を行に追加します
+func (l *textLine) addAll(pdf *Document, nbrs []noBrRun) error {
+	for _, nbr := range nbrs {
+		s, err := nbr.size(pdf)
+		if err != nil {
+			return err
+		}
+		l.add(pdf, nbr, s)
+	}
+	return nil
 }
 
 func (t *Text) AddRun(run *TextRun) *Text {
@@ -147,27 +823,65 @@ func (t *Text) SetAlign(align TextAlign) *Text {
 	t.Align = align
 	return t
 }
+func (t *Text) SetAlignLast(align TextAlign) *Text {
+	t.AlignLast = align
+	return t
+}
+func (t *Text) SetMaxLines(n int) *Text {
+	t.MaxLines = n
+	return t
+}
+func (t *Text) SetTextOverflow(o TextOverflow) *Text {
+	t.TextOverflow = o
+	return t
+}
+func (t *Text) SetKinsoku(k Kinsoku) *Text {
+	t.Kinsoku = k
+	return t
+}
+func (t *Text) SetHangingPunctuation(hanging bool) *Text {
+	t.HangingPunctuation = hanging
+	return t
+}
 
 func NewText(runs ...*TextRun) *Text {
 	t := &Text{
-		Runs: runs,
+		TextOverflow: TextOverflowClip,
+		Kinsoku:      KinsokuPushOut,
+		Runs:         runs,
 	}
 	t.flexItemCommon.init(t)
 	return t
 }
 
-func (t *Text) drawContent(pdf *gopdf.GoPdf, r rect) (err error) {
+func (t *Text) drawContent(pdf *Document, r rect, cb containingBlock, clip *rect) (err error) {
 	defer wrap(&err, "text.drawContent")
 
-	lines, err := t.splitLines(pdf, r.w)
+	lines, err := t.splitLines(pdf, r.w, cb.fontSize)
 	if err != nil {
 		return err
 	}
 
-	pdf.SetXY(r.x, r.y)
+	y := r.y
 	for _, line := range lines {
-		switch t.Align {
-		case TextAlignBegin:
+		// 行ボックス全体がクリップ領域に含まれない行は描画しない
+		if clip != nil && (y < clip.y-clipEpsilon || y+line.size.h > clip.y+clip.h+clipEpsilon) {
+			y += line.size.h
+			continue
+		}
+
+		align := t.Align
+		if align == TextAlignJustify && line.endOfParagraph {
+			align = t.AlignLast
+		}
+		if align == TextAlignJustify {
+			if err := line.justify(pdf, r.w); err != nil {
+				return err
+			}
+		}
+
+		switch align {
+		case TextAlignBegin, TextAlignJustify:
 			pdf.SetX(r.x)
 		case TextAlignCenter:
 			pdf.SetX(r.x + (r.w-line.size.w)/2)
@@ -175,20 +889,43 @@ func (t *Text) drawContent(pdf *gopdf.GoPdf, r rect) (err error) {
 			pdf.SetX(r.x + r.w - line.size.w)
 		}
 		for _, nbr := range line.nbrs {
-			if err := nbr.draw(pdf); err != nil {
+			if clip != nil {
+				// クリップ領域に収まる部分のみを描画し、次の noBrRun の位置は切り詰める前の幅で求める
+				x := pdf.GetX()
+				s, err := nbr.size(pdf)
+				if err != nil {
+					return err
+				}
+				visible, vx, err := nbr.clip(pdf, x, *clip)
+				if err != nil {
+					return err
+				}
+				if visible != nil {
+					pdf.SetXY(vx, y+line.ascent-visible.ascent(pdf))
+					if err := visible.draw(pdf, clip); err != nil {
+						return err
+					}
+				}
+				pdf.SetX(x + s.w)
+				continue
+			}
+
+			// ベースラインを揃える
+			pdf.SetY(y + line.ascent - nbr.ascent(pdf))
+			if err := nbr.draw(pdf, nil); err != nil {
 				return err
 			}
 		}
-		pdf.Br(line.size.h)
+		y += line.size.h
 	}
 
 	return nil
 }
 
-func (t *Text) getContentSize(pdf *gopdf.GoPdf, contentBoxMax size) (s size, err error) {
+func (t *Text) getContentSize(pdf *Document, contentBoxMax containingBlock) (s size, err error) {
 	defer wrap(&err, "text.getContentSize")
 
-	lines, err := t.splitLines(pdf, contentBoxMax.w)
+	lines, err := t.splitLines(pdf, contentBoxMax.w, contentBoxMax.fontSize)
 	if err != nil {
 		return size{}, err
 	}
@@ -201,48 +938,198 @@ func (t *Text) getContentSize(pdf *gopdf.GoPdf, contentBoxMax size) (s size, err
 	return s, nil
 }
 
+// getContentBaseline は最初の行のベースラインの位置を返します
+func (t *Text) getContentBaseline(pdf *Document, contentBox containingBlock) (float64, error) {
+	lines, err := t.splitLines(pdf, contentBox.w, contentBox.fontSize)
+	if err != nil {
+		return 0, err
+	}
+	if len(lines) == 0 {
+		return contentBox.h, nil
+	}
+	return lines[0].ascent, nil
+}
+
 // splitLinesは Runs を行ごとに区切り、 [][]TextRunを返します。
 // 返されるスライスは行を表しており、その要素は行に含まれるRunです。
 // 下記のルールが考慮されます
-// [ ] Textの幅
+// [v] Textの幅
 // [v] Runに含まれる改行コード
-// [ ] 禁則処理
-// [ ]  - 連続する欧文文字と空白
-// [ ]  - 句読点や約物
-func (t *Text) splitLines(pdf *gopdf.GoPdf, widthLimit float64) ([]textLine, error) {
-	lines := []textLine{}
+// [v] 禁則処理
+// [v]  - 連続する欧文文字と空白
+// [v]  - 句読点や約物
+// [v] MaxLines による省略
+// fontSize は Text のフォントサイズで、TextRun の % と em の基準となります
+func (t *Text) splitLines(pdf *Document, widthLimit float64, fontSize float64) ([]textLine, error) {
+	rule := lineBreakRule{kinsoku: t.Kinsoku, hanging: t.HangingPunctuation}
+
+	// 改行コードで段落に区切る。フォントが字形を持たない文字は代替フォントで描画する
+	paragraphs := [][]noBrRun{}
 	for _, r := range t.Runs {
-		for i, nbr := range r.splitWithNewline() {
-			if len(lines) == 0 || i != 0 {
-				lines = append(lines, textLine{})
+		for i, nbr := range r.splitWithNewline(fontSize) {
+			if len(paragraphs) == 0 || i != 0 {
+				paragraphs = append(paragraphs, nil)
 			}
+			paragraphs[len(paragraphs)-1] = append(paragraphs[len(paragraphs)-1], nbr.splitWithFont(pdf)...)
+		}
+	}
 
-			for {
-				line := &lines[len(lines)-1]
-
-				nbr1, nbr2, err := nbr.splitWithWidth(pdf, widthLimit-line.size.w)
-				if err != nil {
-					return nil, err
-				}
-
-				s, err := nbr1.size(pdf)
-				if err != nil {
-					return nil, err
-				}
+	lines := []textLine{}
+	for n, nbrs := range paragraphs {
+		split, err := newParagraph(nbrs, rule.kinsoku).splitLines(pdf, widthLimit, rule)
+		if err != nil {
+			return nil, err
+		}
+		for i, nbrs := range split {
+			lines = append(lines, textLine{endOfParagraph: i == len(split)-1 && t.isParagraphEnd(n), nbrs: nbrs})
+		}
+	}
 
-				line.nbrs = append(line.nbrs, *nbr1)
-				line.size.w += s.w
-				line.size.h = math.Max(line.size.h, s.h)
+	// 行末の空白を取り除いて行のサイズを求め、ぶら下げや追い込みを反映する
+	for i := range lines {
+		if err := lines[i].finish(pdf, widthLimit, rule); err != nil {
+			return nil, err
+		}
+	}
 
-				if nbr2 != nil {
-					lines = append(lines, textLine{})
-					nbr = *nbr2
-				} else {
-					break
-				}
+	if t.MaxLines > 0 && len(lines) > t.MaxLines {
+		lines = lines[:t.MaxLines]
+		if t.TextOverflow == TextOverflowEllipsis {
+			last, err := truncateWithEllipsis(pdf, lines[len(lines)-1], widthLimit)
+			if err != nil {
+				return nil, err
 			}
+			lines[len(lines)-1] = last
 		}
 	}
 
 	return lines, nil
 }
+
+// isParagraphEnd は改行コードで区切られた n 番目の行が段落の最終行かどうかを返します。
+// 分割された Text では、元の Text で段落の途中だった行を最終行として扱いません
+func (t *Text) isParagraphEnd(n int) bool {
+	if t.paragraphEnds == nil {
+		return true
+	}
+	return n < len(t.paragraphEnds) && t.paragraphEnds[n]
+}
+
+// truncateWithEllipsis は行の末尾に省略記号を付け、widthLimit に収まるよう切り詰めた行を返します。
+// 省略記号は切り詰めた位置の noBrRun と同じスタイルで描画されます
+func truncateWithEllipsis(pdf *Document, line textLine, widthLimit float64) (textLine, error) {
+	if len(line.nbrs) == 0 {
+		return line, nil
+	}
+	if widthLimit < 0 {
+		widthLimit = math.Inf(1)
+	}
+
+	truncated := textLine{}
+	mark := line.nbrs[len(line.nbrs)-1]
+	for _, nbr := range line.nbrs {
+		mark = nbr
+		mark.Text = ellipsis
+		ms, err := mark.size(pdf)
+		if err != nil {
+			return textLine{}, err
+		}
+
+		s, err := nbr.size(pdf)
+		if err != nil {
+			return textLine{}, err
+		}
+		remains := widthLimit - ms.w - truncated.size.w
+		if s.w <= remains {
+			truncated.add(pdf, nbr, s)
+			continue
+		}
+
+		// この noBrRun の途中で切り詰める
+		head, _, err := nbr.splitWithCharWidth(pdf, remains)
+		if err != nil {
+			return textLine{}, err
+		}
+		hs, err := head.size(pdf)
+		if err != nil {
+			return textLine{}, err
+		}
+		if head.Text != "" && hs.w <= remains {
+			truncated.add(pdf, *head, hs)
+		}
+		break
+	}
+
+	ms, err := mark.size(pdf)
+	if err != nil {
+		return textLine{}, err
+	}
+	truncated.add(pdf, mark, ms)
+	return truncated, nil
+}
+
+// getFontSize は em の基準となるフォントサイズとして、最初の TextRun のフォントサイズを返します
+func (t *Text) getFontSize(fontSize float64) float64 {
+	if len(t.Runs) == 0 {
+		return fontSize
+	}
+	return t.Runs[0].resolveFontSize(fontSize)
+}
+
+func (t *Text) getAbsoluteItems() []FlexItem {
+	return nil
+}
+
+// clone は Runs を lines に差し替えた Text の複製を返します
+func (t *Text) clone(lines []textLine) *Text {
+	c := *t
+	c.self = &c
+	c.Runs = linesToRuns(lines)
+	c.paragraphEnds = make([]bool, len(lines))
+	for i, line := range lines {
+		c.paragraphEnds[i] = line.endOfParagraph
+	}
+	return &c
+}
+
+// splitContent は Text を行の境界でページに収まる部分とそれ以外に分割します。
+func (t *Text) splitContent(pdf *Document, contentBoxMax containingBlock, pageTop bool) (head, tail FlexItem, err error) {
+	defer wrap(&err, "text.splitContent")
+
+	lines, err := t.splitLines(pdf, contentBoxMax.w, contentBoxMax.fontSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	n := 0
+	for remains := contentBoxMax.h; n < len(lines) && lines[n].size.h <= remains; n++ {
+		remains -= lines[n].size.h
+	}
+	if n == 0 && pageTop && len(lines) != 0 {
+		n = 1 // ページの先頭では少なくとも1行を配置する
+	}
+
+	switch {
+	case n == len(lines) && (n != 0 || pageTop):
+		return t, nil, nil // 行の無いテキストもページの先頭ではそのまま配置する
+	case n == 0:
+		return nil, t, nil
+	default:
+		return t.clone(lines[:n]), t.clone(lines[n:]), nil
+	}
+}
+
+// linesToRuns は行のリストを、改行コードで行を区切った TextRun のリストに戻します
+func linesToRuns(lines []textLine) []*TextRun {
+	runs := []*TextRun{}
+	for i, line := range lines {
+		for _, nbr := range line.nbrs {
+			run := nbr.TextRun
+			runs = append(runs, &run)
+		}
+		if i != len(lines)-1 && len(runs) != 0 {
+			runs[len(runs)-1].Text += "\n"
+		}
+	}
+	return runs
+}
@@ -0,0 +1,335 @@
+package flexpdf
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/signintech/gopdf"
+)
+
+// 1文字も収まらない幅で描画してもパニックしないこと
+func TestNarrowText(t *testing.T) {
+	items := map[string]*Box{
+		"wrap": NewRowBox(NewText(NewRun("W").SetFontSize(30)).SetWidth(5)),
+		"clip": NewRowBox(NewText(NewRun("W").SetFontSize(30)).SetWidth(5).SetOverflow(OverflowHidden)),
+	}
+	for name, item := range items {
+		item := item
+		t.Run(name, func(t *testing.T) {
+			if err := Draw(newTestPdf(t), item, gopdf.PageSizeA4); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+// 行の無いテキストがページに収まらなくてもパニックしないこと
+func TestEmptyText(t *testing.T) {
+	if err := Draw(newTestPdf(t), NewColumnBox(NewText().SetPadding(1000)), gopdf.PageSizeA4); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSplitWithCharWidth(t *testing.T) {
+	pdf := newTestPdf(t)
+	r := noBrRun{TextRun: *NewRun("WW").SetFontSize(30)}
+
+	head, tail, err := r.splitWithCharWidth(pdf, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.Text != "W" || tail == nil || tail.Text != "W" {
+		t.Fatalf("got %+v, %+v", head, tail)
+	}
+
+	head, tail, err = tail.splitWithCharWidth(pdf, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.Text != "W" || tail != nil {
+		t.Fatalf("got %+v, %+v", head, tail)
+	}
+}
+
+// lineTexts は各行の文字列を返します
+func lineTexts(lines []textLine) []string {
+	texts := []string{}
+	for _, line := range lines {
+		text := ""
+		for _, nbr := range line.nbrs {
+			text += nbr.Text
+		}
+		texts = append(texts, text)
+	}
+	return texts
+}
+
+// measure は TextRun のスタイルで text を描画した場合の幅を返します
+func measure(t *testing.T, pdf *Document, r *TextRun, text string) float64 {
+	t.Helper()
+	run := *r
+	run.Text = text
+	lines, err := NewText(&run).splitLines(pdf, -1, defaultFontSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return lines[0].size.w
+}
+
+func TestSplitLines(t *testing.T) {
+	pdf := newTestPdf(t)
+	run := NewRun("")
+
+	tests := []struct {
+		name  string
+		text  *Text
+		width float64
+		want  []string
+	}{
+		{
+			// TextRun の境界は改行の機会ではない
+			name:  "run boundary",
+			text:  NewText(NewRun("hello wor"), NewRun("ld")),
+			width: measure(t, pdf, run, "hello wor") + 1,
+			want:  []string{"hello", "world"},
+		},
+		{
+			name:  "run boundary after space",
+			text:  NewText(NewRun("hello "), NewRun("world")),
+			width: measure(t, pdf, run, "hello wor"),
+			want:  []string{"hello", "world"},
+		},
+		{
+			// 行頭の空白は取り除く
+			name:  "leading spaces",
+			text:  NewText(NewRun("   verylongword")),
+			width: measure(t, pdf, run, "verylong"),
+			want:  []string{"verylong", "word"},
+		},
+		{
+			name:  "leading spaces after newline",
+			text:  NewText(NewRun("hello\n  world")),
+			width: -1,
+			want:  []string{"hello", "world"},
+		},
+		{
+			name:  "min-content",
+			text:  NewText(NewRun("hello wor"), NewRun("ld")),
+			width: 0,
+			want:  []string{"hello", "world"},
+		},
+		{
+			name:  "empty paragraph",
+			text:  NewText(NewRun("a\n\nb")),
+			width: -1,
+			want:  []string{"a", "", "b"},
+		},
+	}
+	for _, tt := range tests {
+		lines, err := tt.text.splitLines(pdf, tt.width, defaultFontSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := lineTexts(lines); strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// 禁則処理は TextRun の境界をまたいで適用する
+func TestSplitLinesKinsoku(t *testing.T) {
+	pdf := newTestPdf(t)
+	run := NewRun("")
+
+	tests := []struct {
+		name  string
+		text  *Text
+		width float64
+		want  []string
+	}{
+		{
+			name:  "push-out period",
+			text:  NewText(NewRun("テストです"), NewRun("。")),
+			width: measure(t, pdf, run, "テストです"),
+			want:  []string{"テストで", "す。"},
+		},
+		{
+			name:  "push-out closing bracket",
+			text:  NewText(NewRun("「テスト"), NewRun("」")),
+			width: measure(t, pdf, run, "「テスト"),
+			want:  []string{"「テス", "ト」"},
+		},
+		{
+			name:  "push-out small kana",
+			text:  NewText(NewRun("テストち"), NewRun("ょ")),
+			width: measure(t, pdf, run, "テストち"),
+			want:  []string{"テスト", "ちょ"},
+		},
+		{
+			name:  "push-out opening bracket",
+			text:  NewText(NewRun("テスト「"), NewRun("あ」")),
+			width: measure(t, pdf, run, "テスト「"),
+			want:  []string{"テスト", "「あ」"},
+		},
+		{
+			name:  "push-in",
+			text:  NewText(NewRun("「テスト」です"), NewRun("。")).SetKinsoku(KinsokuPushIn),
+			width: measure(t, pdf, run, "「テスト」です"),
+			want:  []string{"「テスト」です。"},
+		},
+		{
+			name:  "hanging",
+			text:  NewText(NewRun("テストです"), NewRun("。")).SetHangingPunctuation(true),
+			width: measure(t, pdf, run, "テストです"),
+			want:  []string{"テストです。"},
+		},
+		{
+			name:  "none",
+			text:  NewText(NewRun("テストです"), NewRun("。")).SetKinsoku(KinsokuNone),
+			width: measure(t, pdf, run, "テストです"),
+			want:  []string{"テストです", "。"},
+		},
+	}
+	for _, tt := range tests {
+		lines, err := tt.text.splitLines(pdf, tt.width, defaultFontSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := lineTexts(lines); strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSplitWithFont(t *testing.T) {
+	pdf := newTestPdf(t)
+
+	// "emoji" は flexpdf に登録されていないため、全ての字形を持つものとみなされる
+	r := noBrRun{TextRun: *NewRun("ab 😀 cd").SetFallbackFamilies("emoji")}
+	got := []string{}
+	for _, nbr := range r.splitWithFont(pdf) {
+		got = append(got, nbr.FontFamily+":"+nbr.Text)
+	}
+	want := []string{":ab ", "emoji:😀 ", ":cd"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// フォントの切り替わる位置は改行の機会ではない
+func TestParagraphFontBoundary(t *testing.T) {
+	pdf := newTestPdf(t)
+	if err := pdf.AddTTFFontData("ipaexm", ipaexmBytes); err != nil {
+		t.Fatal(err)
+	}
+
+	nbrs := []noBrRun{
+		NewRun("hello wor").splitWithNewline(defaultFontSize)[0],
+		NewRun("ld").SetFontFamily("ipaexm").splitWithNewline(defaultFontSize)[0],
+	}
+	width := measure(t, pdf, NewRun(""), "hello wor") + 1
+	lines, err := newParagraph(nbrs, KinsokuPushOut).splitLines(pdf, width, lineBreakRule{kinsoku: KinsokuPushOut})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []string{}
+	for _, line := range lines {
+		texts := []string{}
+		for _, nbr := range line {
+			texts = append(texts, nbr.FontFamily+":"+nbr.Text)
+		}
+		got = append(got, strings.Join(texts, ","))
+	}
+	want := []string{":hello ", ":wor,ipaexm:ld"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestNoBrRunClip(t *testing.T) {
+	pdf := newTestPdf(t)
+	run := NewRun("abcd")
+	a, abc := measure(t, pdf, run, "a"), measure(t, pdf, run, "abc")
+	clip := rect{x: 100, y: 0, w: abc - a, h: 100}
+
+	// 左端にかかる文字と右端をはみ出す文字を取り除く
+	r := run.splitWithNewline(defaultFontSize)[0]
+	visible, x, err := r.clip(pdf, 100-a/2, clip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if visible == nil || visible.Text != "b" || math.Abs(x-(100+a/2)) > 1e-6 {
+		t.Fatalf("got %+v at %v", visible, x)
+	}
+
+	visible, x, err = r.clip(pdf, 100-a, clip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if visible == nil || visible.Text != "bc" || math.Abs(x-100) > 1e-6 {
+		t.Fatalf("got %+v at %v", visible, x)
+	}
+
+	// 合成した太字のはみ出しもクリップ領域に含める
+	bold := NewRun("abcd").SetFontWeight(FontWeightBold).splitWithNewline(defaultFontSize)[0]
+	visible, _, err = bold.clip(pdf, 100-a, clip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if visible == nil || visible.Text != "b" {
+		t.Fatalf("got %+v", visible)
+	}
+
+	// 合成した斜体はディセンダー側が左にはみ出す
+	oblique := NewRun("abcd").SetFontStyle(FontStyleItalic).splitWithNewline(defaultFontSize)[0]
+	visible, x, err = oblique.clip(pdf, 100, rect{x: 100, y: 0, w: 1000, h: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if visible == nil || visible.Text != "bcd" || math.Abs(x-(100+a)) > 1e-6 {
+		t.Fatalf("got %+v at %v", visible, x)
+	}
+}
+
+func TestTruncateWithEllipsis(t *testing.T) {
+	pdf := newTestPdf(t)
+	run := NewRun("")
+
+	tests := []struct {
+		name  string
+		runs  []*TextRun
+		width float64
+		want  string
+	}{
+		{"fits", []*TextRun{NewRun("hello")}, -1, "hello" + ellipsis},
+		{"cut", []*TextRun{NewRun("hello world")}, measure(t, pdf, run, "hello"+ellipsis) + 0.001, "hello" + ellipsis},
+		{"cut in second run", []*TextRun{NewRun("hello "), NewRun("world")}, measure(t, pdf, run, "hello wo"+ellipsis) + 0.001, "hello wo" + ellipsis},
+		{"only ellipsis", []*TextRun{NewRun("hello")}, measure(t, pdf, run, ellipsis) + 0.001, ellipsis},
+	}
+	for _, tt := range tests {
+		lines, err := NewText(tt.runs...).splitLines(pdf, -1, defaultFontSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := truncateWithEllipsis(pdf, lines[0], tt.width)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if text := lineTexts([]textLine{got})[0]; text != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, text, tt.want)
+		}
+		if tt.width >= 0 && got.size.w > tt.width {
+			t.Errorf("%s: width %v exceeds %v", tt.name, got.size.w, tt.width)
+		}
+	}
+}
+
+func TestUnknownDecorationLine(t *testing.T) {
+	pdf := newTestPdf(t)
+	run := NewRun("abc").SetDecoration(NewTextDecoration(nil, BorderStyleSolid, 0, "blink"))
+	r := run.splitWithNewline(defaultFontSize)[0]
+	if err := r.drawDecoration(pdf, 0, 0, 100, nil); err == nil {
+		t.Error("want an error for an unknown decoration line")
+	}
+}
@@ -0,0 +1,110 @@
+package flexpdf
+
+import (
+	"math"
+	"testing"
+
+	"github.com/signintech/gopdf"
+)
+
+func TestAspectRatio(t *testing.T) {
+	pdf := newTestPdf(t)
+	page := containingBlock{size: size{w: 500, h: 500}, fontSize: defaultFontSize}
+
+	// 幅・高さとも未指定の場合は内容の幅から高さを求める
+	text := NewText(NewRun("x")).SetAspectRatio(1)
+	ps, err := text.getPreferredSize(pdf, page, page.size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := measure(t, pdf, NewRun(""), "x"); math.Abs(ps.w-w) > 1e-6 || math.Abs(ps.h-w) > 1e-6 {
+		t.Errorf("got %+v, want %v x %v", ps, w, w)
+	}
+
+	// 伸縮した場合は伸縮後の幅から高さを求める
+	box := NewRowBox(NewText(NewRun("x")).SetFlex(1, 1, 0).SetAspectRatio(2))
+	lines, err := box.layoutLines(pdf, containingBlock{size: size{w: 200, h: 500}, fontSize: defaultFontSize}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ps := lines[0].sizes[0]; math.Abs(ps.w-200) > 1e-6 || math.Abs(ps.h-100) > 1e-6 {
+		t.Errorf("got %+v, want 200 x 100", ps)
+	}
+
+	// 縦方向のボックスで stretch する場合は広げた幅から高さを求める
+	box = NewColumnBox(NewColumnBox().SetAspectRatio(4))
+	lines, err = box.layoutLines(pdf, containingBlock{size: size{w: 200, h: 500}, fontSize: defaultFontSize}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ps := lines[0].sizes[0]; math.Abs(ps.w-200) > 1e-6 || math.Abs(ps.h-50) > 1e-6 {
+		t.Errorf("got %+v, want 200 x 50", ps)
+	}
+}
+
+func TestFontSizeInheritance(t *testing.T) {
+	pdf := newTestPdf(t)
+	page := containingBlock{size: size{w: 500, h: 500}, fontSize: defaultFontSize}
+
+	tests := []struct {
+		name string
+		box  *Box
+		want size
+	}{
+		// em は継承したフォントサイズを基準とする
+		{"box em", NewColumnBox().SetFontSize(20).SetPaddingLength(Em(1)), size{w: 40, h: 40}},
+		{"nested box em", NewColumnBox(NewColumnBox().SetPaddingLength(Em(1))).SetFontSize(20), size{w: 40, h: 40}},
+		{"relative font size", NewColumnBox(NewColumnBox().SetFontSizeLength(Percent(50)).SetPaddingLength(Em(1))).SetFontSize(20), size{w: 20, h: 20}},
+		// TextRun のフォントサイズも継承する
+		{"text run", NewColumnBox(NewText(NewRun(""))).SetFontSize(20), size{w: 0, h: 20}},
+		{"text run em", NewColumnBox(NewText(NewRun("").SetFontSizeLength(Em(1.5)))).SetFontSize(20), size{w: 0, h: 30}},
+	}
+	for _, tt := range tests {
+		ps, err := tt.box.getPreferredSize(pdf, page, page.size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(ps.w-tt.want.w) > 1e-6 || math.Abs(ps.h-tt.want.h) > 1e-6 {
+			t.Errorf("%s: got %+v, want %+v", tt.name, ps, tt.want)
+		}
+	}
+}
+
+func TestPercentHeight(t *testing.T) {
+	pdf := newTestPdf(t)
+	page := containingBlock{size: size{w: 500, h: 500}, fontSize: defaultFontSize}
+
+	// 親の高さが内容によって決まる場合、% の高さは auto として扱う
+	box := NewColumnBox(NewColumnBox().SetHeightLength(Percent(50)))
+	ps, err := box.getPreferredSize(pdf, page, page.size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ps.h != 0 {
+		t.Errorf("got %+v, want height 0", ps)
+	}
+
+	box = NewColumnBox(NewColumnBox().SetHeightLength(Percent(50))).SetHeight(100)
+	lines, err := box.layoutLines(pdf, containingBlock{size: size{w: 500, h: 100}, fontSize: defaultFontSize}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := lines[0].sizes[0].h; h != 50 {
+		t.Errorf("got %v, want 50", h)
+	}
+}
+
+// 入れ子のアイテムの計測は Draw の間キャッシュされるため、深さに対して指数的に遅くならない
+func TestDeepNesting(t *testing.T) {
+	pdf := newTestPdf(t)
+	var item FlexItem = NewText(NewRun("hello world"))
+	for i := 0; i < 40; i++ {
+		item = NewRowBox(item)
+	}
+	if err := Draw(pdf, NewColumnBox(item), gopdf.PageSizeA4); err != nil {
+		t.Fatal(err)
+	}
+	if pdf.contentSizes != nil {
+		t.Error("cache is not released after Draw")
+	}
+}
@@ -0,0 +1,137 @@
+package flexpdf
+
+// Unit は Length の単位です
+type Unit int
+
+const (
+	UnitPt      Unit = iota // ポイント（1/72インチ）
+	UnitAuto                // 自動（マージンでは余白を吸収します）
+	UnitMm                  // ミリメートル
+	UnitCm                  // センチメートル
+	UnitIn                  // インチ
+	UnitPercent             // 包含ブロック（親の Box のコンテンツボックス）に対する割合。FontSize では親のフォントサイズに対する割合
+	UnitEm                  // アイテムのフォントサイズに対する比率。FontSize では親のフォントサイズに対する比率
+)
+
+// defaultFontSize は Draw に渡した Box が継承するフォントサイズです
+const defaultFontSize = 10
+
+// Length は単位付きの長さです
+type Length struct {
+	Value float64
+	Unit  Unit
+}
+
+// Auto は自動の長さです
+var Auto = Length{Unit: UnitAuto}
+
+// Pt はポイント単位の長さを返します
+func Pt(v float64) Length {
+	return Length{Value: v, Unit: UnitPt}
+}
+
+// Mm はミリメートル単位の長さを返します
+func Mm(v float64) Length {
+	return Length{Value: v, Unit: UnitMm}
+}
+
+// Cm はセンチメートル単位の長さを返します
+func Cm(v float64) Length {
+	return Length{Value: v, Unit: UnitCm}
+}
+
+// In はインチ単位の長さを返します
+func In(v float64) Length {
+	return Length{Value: v, Unit: UnitIn}
+}
+
+// Percent は包含ブロックに対する割合（%）の長さを返します
+func Percent(v float64) Length {
+	return Length{Value: v, Unit: UnitPercent}
+}
+
+// Em はフォントサイズに対する比率の長さを返します
+func Em(v float64) Length {
+	return Length{Value: v, Unit: UnitEm}
+}
+
+func (l Length) IsAuto() bool {
+	return l.Unit == UnitAuto
+}
+
+// resolve はポイント単位の値を返します。
+// base は % の基準となる長さ、em は em の基準となるフォントサイズです。auto は 0 となります
+func (l Length) resolve(base, em float64) float64 {
+	switch l.Unit {
+	case UnitPt:
+		return l.Value
+	case UnitAuto:
+		return 0
+	case UnitMm:
+		return l.Value * 72 / 25.4
+	case UnitCm:
+		return l.Value * 72 / 2.54
+	case UnitIn:
+		return l.Value * 72
+	case UnitPercent:
+		return base * l.Value / 100
+	case UnitEm:
+		return em * l.Value
+	default:
+		panic(l.Unit)
+	}
+}
+
+// resolveSize は resolve と同様ですが、auto の場合は -1 を返します
+func (l Length) resolveSize(base, em float64) float64 {
+	if l.IsAuto() {
+		return -1
+	}
+	return l.resolve(base, em)
+}
+
+// ptOrAuto は負の値を auto として、ポイント単位の長さを返します
+func ptOrAuto(v float64) Length {
+	if v < 0 {
+		return Auto
+	}
+	return Pt(v)
+}
+
+// LengthSpacing は Length で指定する上下左右の幅です
+type LengthSpacing TRBL[Length]
+
+// UniformedSpacing は上下左右が同じ幅の LengthSpacing を返します
+func UniformedSpacing(l Length) LengthSpacing {
+	return LengthSpacing{l, l, l, l}
+}
+
+// resolve はポイント単位の Spacing を返します。
+// CSS と同様に、上下の % も包含ブロックの幅を基準とします
+func (s LengthSpacing) resolve(base, em float64) Spacing {
+	return Spacing{
+		Top:    s.Top.resolve(base, em),
+		Right:  s.Right.resolve(base, em),
+		Bottom: s.Bottom.resolve(base, em),
+		Left:   s.Left.resolve(base, em),
+	}
+}
+
+// autos は各辺が auto かどうかを返します
+func (s LengthSpacing) autos() TRBL[bool] {
+	return TRBL[bool]{
+		Top:    s.Top.IsAuto(),
+		Right:  s.Right.IsAuto(),
+		Bottom: s.Bottom.IsAuto(),
+		Left:   s.Left.IsAuto(),
+	}
+}
+
+// pts は float64 の値をポイント単位の Length に変換します
+func pts(values ...float64) []Length {
+	lengths := make([]Length, len(values))
+	for i, v := range values {
+		lengths[i] = Pt(v)
+	}
+	return lengths
+}
@@ -0,0 +1,95 @@
+package flexpdf
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Kinsoku は行頭・行末禁則の調整方法です
+// https://www.w3.org/TR/jlreq/#line_breaking_rules
+type Kinsoku string
+
+const (
+	KinsokuNone    Kinsoku = "none"     // 禁則処理を行いません
+	KinsokuPushOut Kinsoku = "push-out" // 禁則文字の直前の文字ごと次の行に送ります（追い出し）
+	KinsokuPushIn  Kinsoku = "push-in"  // 行頭禁則文字を前の行に収め、その行の文字間を詰めます（追い込み）。詰めきれない場合は追い出します
+)
+
+// lineBreakRule は行を分割する際の規則です
+type lineBreakRule struct {
+	kinsoku Kinsoku
+	hanging bool // 句読点のぶら下げ
+}
+
+// breakOpportunities は runes の各位置の直前で改行できるかどうかを返します。
+// UAX #14 を簡略化したもので、空白の後、単語中のハイフンの後、CJK の文字の前後で改行できます。
+// kinsoku が KinsokuNone でなければ、行頭禁則文字の前と行末禁則文字の後では改行しません。
+// 末尾（len(runes) の位置）は常に改行できます
+// https://www.unicode.org/reports/tr14/
+func breakOpportunities(runes []rune, kinsoku Kinsoku) []bool {
+	breaks := make([]bool, len(runes)+1)
+	for i := 1; i < len(runes); i++ {
+		prev, next := runes[i-1], runes[i]
+		switch {
+		case isBreakSpace(next):
+			// 空白の前では改行しない（連続する空白の後で改行する）
+		case kinsoku != KinsokuNone && (isNoBreakBefore(next) || isNoBreakAfter(prev)):
+			// 禁則処理
+		case isBreakSpace(prev):
+			breaks[i] = true
+		case prev == '-' && i >= 2 && isWordRune(runes[i-2]) && isWordRune(next):
+			breaks[i] = true
+		case isCJK(prev) || isCJK(next):
+			breaks[i] = true
+		}
+	}
+	breaks[len(runes)] = true
+	return breaks
+}
+
+// isBreakSpace は改行の機会となる空白かどうかを返します。ノーブレークスペースは含みません
+func isBreakSpace(r rune) bool {
+	return r == ' ' || r == '\t'
+}
+
+// isWordRune は単語を構成する文字かどうかを返します
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// isCJK は任意の位置で改行できる CJK の文字（漢字、かな、全角の記号）かどうかを返します
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) ||
+		(0x3000 <= r && r <= 0x303F) || // CJK の記号と句読点
+		(0xFF00 <= r && r <= 0xFFEF) // 半角・全角形
+}
+
+// 行頭禁則文字（終わり括弧類、句読点、中点類、区切り約物、繰返し記号、長音記号、小書きの仮名）
+// https://www.w3.org/TR/jlreq/#characters_not_starting_a_line
+const noBreakBefore = ")]}）〕］｝〉》」』】〙〗〟’”｠»" +
+	",.:;!?、。，．・：；？！‼⁇⁈⁉" +
+	"ヽヾゝゞ々〻ー゠‐〜～" +
+	"ぁぃぅぇぉっゃゅょゎゕゖァィゥェォッャュョヮヵヶㇰㇱㇲㇳㇴㇵㇶㇷㇸㇹㇺㇻㇼㇽㇾㇿ"
+
+// 行末禁則文字（始め括弧類）
+// https://www.w3.org/TR/jlreq/#characters_not_ending_a_line
+const noBreakAfter = "([{（〔［｛〈《「『【〘〖〝‘“｟«"
+
+// ぶら下げの対象となる句読点
+const hangingPunctuation = ",.、。，．"
+
+func isNoBreakBefore(r rune) bool {
+	return strings.ContainsRune(noBreakBefore, r)
+}
+func isNoBreakAfter(r rune) bool {
+	return strings.ContainsRune(noBreakAfter, r)
+}
+func isHangingPunctuation(r rune) bool {
+	return strings.ContainsRune(hangingPunctuation, r)
+}
+
+// isFullWidthPunctuation は字面の半分をアキとして詰められる全角の約物かどうかを返します。
+// 追い込みで詰められる量の目安に使用します
+func isFullWidthPunctuation(r rune) bool {
+	return isCJK(r) && unicode.IsPunct(r)
+}
@@ -0,0 +1,39 @@
+package flexpdf
+
+import "testing"
+
+func TestBreakOpportunities(t *testing.T) {
+	tests := []struct {
+		text    string
+		kinsoku Kinsoku
+		want    string // 改行できる位置を | で示したもの
+	}{
+		{"hello world", KinsokuNone, "hello |world|"},
+		{"hello   world", KinsokuNone, "hello   |world|"},
+		{"well-known", KinsokuNone, "well-|known|"},
+		{"-5", KinsokuNone, "-5|"},
+		{"日本語", KinsokuNone, "日|本|語|"},
+		{"abc日本", KinsokuNone, "abc|日|本|"},
+		{"です。", KinsokuNone, "で|す|。|"},
+		{"です。", KinsokuPushOut, "で|す。|"},
+		{"「あ」", KinsokuPushOut, "「あ」|"},
+		{"ちょっと", KinsokuPushOut, "ちょっ|と|"},
+	}
+	for _, tt := range tests {
+		runes := []rune(tt.text)
+		breaks := breakOpportunities(runes, tt.kinsoku)
+		got := ""
+		for i, r := range runes {
+			if i != 0 && breaks[i] {
+				got += "|"
+			}
+			got += string(r)
+		}
+		if breaks[len(runes)] {
+			got += "|"
+		}
+		if got != tt.want {
+			t.Errorf("%q (%s): got %q, want %q", tt.text, tt.kinsoku, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package flexpdf
+
+import (
+	"math"
+)
+
+// Overflow はパディングボックスからはみ出した内容の扱いです
+// https://www.w3.org/TR/css-overflow-3/#overflow-properties
+//
+// gopdf（v0.33 まで）はクリッピングパスを設定する API も、コンテンツストリームに任意の演算子を書き込む手段も持たないため、
+// OverflowHidden はソフトウェア的に実現します。背景と罫線はクリップ領域と重なる部分のみを描画します。
+// テキストは行ボックス全体がクリップ領域に含まれる行のみを描画し、左右は（合成した太字や斜体のはみ出しを含めて）収まる文字単位で切り詰めます。
+// そのため、上下の端にかかる行や左右の端にかかる文字は、一部が見える位置にあっても描画されません
+type Overflow string
+
+const (
+	OverflowVisible Overflow = "visible"
+	OverflowHidden  Overflow = "hidden"
+)
+
+// clipEpsilon はクリップ領域からのはみ出しとして扱わない、丸め誤差程度の幅です
+const clipEpsilon = 1e-6
+
+// intersectClip はクリップ領域 clip を r の内側に限定したクリップ領域を返します。
+// clip が nil（クリップなし）の場合は r を返します
+func intersectClip(clip *rect, r rect) *rect {
+	if clip != nil {
+		r = r.intersect(*clip)
+	}
+	return &r
+}
+
+// clipLine は水平・垂直な線分をクリップ領域 clip に収まる範囲に切り詰めます。
+// クリップ領域と重ならない場合は ok が false となります
+func clipLine(clip *rect, x1, y1, x2, y2 float64) (_, _, _, _ float64, ok bool) {
+	if clip == nil {
+		return x1, y1, x2, y2, true
+	}
+
+	switch {
+	case y1 == y2:
+		if y1 < clip.y || y1 > clip.y+clip.h {
+			return 0, 0, 0, 0, false
+		}
+		x1, x2 = math.Max(math.Min(x1, x2), clip.x), math.Min(math.Max(x1, x2), clip.x+clip.w)
+		return x1, y1, x2, y2, x1 < x2
+	case x1 == x2:
+		if x1 < clip.x || x1 > clip.x+clip.w {
+			return 0, 0, 0, 0, false
+		}
+		y1, y2 = math.Max(math.Min(y1, y2), clip.y), math.Min(math.Max(y1, y2), clip.y+clip.h)
+		return x1, y1, x2, y2, y1 < y2
+	default:
+		return x1, y1, x2, y2, true
+	}
+}
@@ -0,0 +1,77 @@
+package flexpdf
+
+import (
+	"math"
+)
+
+// Position はアイテムの配置方法です
+// https://www.w3.org/TR/css-position-3/#position-property
+type Position string
+
+const (
+	PositionStatic   Position = "static"   // 通常の配置（Inset は無視されます）
+	PositionRelative Position = "relative" // 通常の位置から Inset だけずらして描画します
+	PositionAbsolute Position = "absolute" // フレックスレイアウトから外し、親のパディングボックスを基準に配置します
+)
+
+// relativeOffset は相対配置のアイテムを通常の位置からずらす量を返します。
+// Left と Right がともに指定されている場合は Left を、Top と Bottom では Top を優先します
+func relativeOffset(inset Spacing, autos TRBL[bool]) (dx, dy float64) {
+	switch {
+	case !autos.Left:
+		dx = inset.Left
+	case !autos.Right:
+		dx = -inset.Right
+	}
+	switch {
+	case !autos.Top:
+		dy = inset.Top
+	case !autos.Bottom:
+		dy = -inset.Bottom
+	}
+	return dx, dy
+}
+
+// drawAbsolute は絶対配置のアイテムを、親のパディングボックス cb を基準に描画します。
+// fontSize は親のフォントサイズです。
+// 対辺のインセットがともに指定され、サイズが auto の場合はその間いっぱいに広げ、
+// そうでなければ内容のサイズとします。インセットがともに auto の場合は始端に配置します
+func drawAbsolute(pdf *Document, item FlexItem, cb rect, fontSize float64, clip *rect) (err error) {
+	defer wrap(&err, "drawAbsolute")
+
+	cbSize := containingBlock{size: size{w: cb.w, h: cb.h}, fontSize: fontSize}
+	inset, autos := item.getInset(cbSize)
+	available := size{
+		w: math.Max(0, cb.w-inset.Left-inset.Right),
+		h: math.Max(0, cb.h-inset.Top-inset.Bottom),
+	}
+
+	ps, err := item.getPreferredSize(pdf, cbSize, available)
+	if err != nil {
+		return err
+	}
+	if !autos.Left && !autos.Right && item.isAutoSize(horizontal) {
+		min, max := item.getSizeLimits(cbSize, horizontal)
+		ps.w = math.Max(math.Min(available.w, max), min)
+
+		// 確定した幅で高さを求め直す
+		ps_, err := item.getPreferredSize(pdf, cbSize, size{w: ps.w, h: available.h})
+		if err != nil {
+			return err
+		}
+		ps.h = ps_.h
+	}
+	if !autos.Top && !autos.Bottom && item.isAutoSize(vertical) {
+		min, max := item.getSizeLimits(cbSize, vertical)
+		ps.h = math.Max(math.Min(available.h, max), min)
+	}
+
+	r := rect{x: cb.x + inset.Left, y: cb.y + inset.Top, w: ps.w, h: ps.h}
+	if autos.Left && !autos.Right {
+		r.x = cb.x + cb.w - inset.Right - ps.w
+	}
+	if autos.Top && !autos.Bottom {
+		r.y = cb.y + cb.h - inset.Bottom - ps.h
+	}
+	return item.draw(pdf, cbSize, r, clip)
+}
@@ -17,16 +17,45 @@ const (
 var (
 	_ FlexItem = &Text{}
 	_ FlexItem = &Box{}
+	_ FlexItem = &Grid{}
+	_ FlexItem = &Table{}
 )
 
 type FlexItem interface {
 	// draw はこのFlexItemを与えられた矩形内に描画します。
-	draw(pdf *gopdf.GoPdf, r rect) error
-	getPreferredSize(pdf *gopdf.GoPdf, marginBoxMax size) (size, error)
+	// cb は包含ブロック（親のコンテンツボックス）のサイズで、% の長さの基準となります。
+	// clip はクリップ領域で、nil の場合はクリップしません
+	draw(pdf *Document, cb containingBlock, r rect, clip *rect) error
+	getPreferredSize(pdf *Document, cb containingBlock, marginBoxMax size) (size, error)
+	// getDefiniteSize は軸 a のサイズを marginBox のその軸のサイズに確定した場合のマージンボックスのサイズを返します。
+	// AspectRatio が指定されていれば、他方の軸のサイズはその比から求めます
+	getDefiniteSize(pdf *Document, cb containingBlock, marginBox size, a axis) (size, error)
+	// getFlexBaseSize はグロー・シュリンクを行う前のマージンボックスのサイズを返します
+	getFlexBaseSize(pdf *Document, cb containingBlock, marginBoxMax size, mainAxis axis) (size, error)
+	// split はこのFlexItemをページ内に収まる部分と次のページ以降に送る部分に分割します。
+	split(pdf *Document, cb containingBlock, marginBoxMax size, pageTop bool) (head, tail FlexItem, err error)
+	// getBaseline はマージンボックスの上端から最初の行のベースラインまでの距離を返します
+	getBaseline(pdf *Document, cb containingBlock, marginBox size) (float64, error)
+	// getMinSize は内容に基づく自動的な最小サイズを返します
+	getMinSize(pdf *Document, cb containingBlock, marginBoxMax size) (size, error)
+	getSizeLimits(cb containingBlock, a axis) (min, max float64)
 	getFlexGrow() float64
+	getFlexShrink() float64
+	getAlignSelf() AlignSelf
+	getOrder() int
+	getPosition() Position
+	// getInset は Inset を包含ブロック cb に対して解決した値と、各辺が auto かどうかを返します
+	getInset(cb containingBlock) (Spacing, TRBL[bool])
+	getGridArea() GridArea
+	// getAutoMargins は auto が指定されたマージンを返します
+	getAutoMargins() TRBL[bool]
+	// isAutoSize は指定した軸のサイズが未指定（内容や stretch によって決まる）かどうかを返します。AspectRatio が指定されていれば常に false です
+	isAutoSize(a axis) bool
+	// hasAutoAspectRatio は幅・高さとも未指定で、AspectRatio によって一方から他方を求めるかどうかを返します
+	hasAutoAspectRatio() bool
 }
 
-func setColor(pdf *gopdf.GoPdf, col color.Color) (err error) {
+func setColor(pdf *Document, col color.Color) (err error) {
 	defer wrap(&err, "setColor")
 
 	r, g, b, a := col.RGBA()
@@ -62,3 +91,16 @@ func wrap(errp *error, format string, args ...any) {
 		*errp = fmt.Errorf("%s: %w", fmt.Sprintf(format, args...), *errp)
 	}
 }
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -1,5 +1,7 @@
 package flexpdf
 
+import "math"
+
 // rect はページ中の矩形を表します
 type rect struct {
 	// TODO sizeを使う
@@ -69,3 +71,10 @@ func (s rect) shrink(spacing Spacing) rect {
 	// TODO negative
 	return s
 }
+
+// intersect は2つの矩形が重なる部分を返します。重ならない場合は幅または高さが0となります
+func (s rect) intersect(o rect) rect {
+	x1, y1 := math.Max(s.x, o.x), math.Max(s.y, o.y)
+	x2, y2 := math.Min(s.x+s.w, o.x+o.w), math.Min(s.y+s.h, o.y+o.h)
+	return rect{x: x1, y: y1, w: math.Max(0, x2-x1), h: math.Max(0, y2-y1)}
+}
@@ -1,5 +1,7 @@
 package flexpdf
 
+import "math"
+
 // sizeは幅と高さを表現する値です
 type size struct {
 	w float64
@@ -47,8 +49,15 @@ func (s size) expand(spacing Spacing) size {
 	return s
 }
 func (s size) shrink(spacing Spacing) size {
-	s.w -= spacing.Left + spacing.Right
-	s.h -= spacing.Top + spacing.Bottom
-	// TODO negative
+	s.w = math.Max(0, s.w-spacing.Left-spacing.Right)
+	s.h = math.Max(0, s.h-spacing.Top-spacing.Bottom)
 	return s
 }
+
+// containingBlock は包含ブロックです。
+// % の基準となるサイズと、子アイテムが継承するフォントサイズを持ちます
+type containingBlock struct {
+	size
+	fontSize   float64 // 継承するフォントサイズ
+	autoHeight bool    // 高さが内容によって決まる（% の高さを解決できない）かどうか
+}